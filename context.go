@@ -1,38 +1,83 @@
 package log
 
-import "context"
+import (
+	"context"
+	"sync"
+
+	"github.com/go-chi/chi/middleware"
+)
 
 type logContextType string
 
 // ContextKeyLogFields is the key for the logging fields context value.
 const ContextKeyLogFields logContextType = "nrfta/go-log/Fields"
 
+// fieldStackRef is the context value holding the logging fields stack. The stack itself
+// is copy-on-write; PushContextFields and PopContextFields replace it under the lock, so
+// every holder of the ref sees the change. ContextWithFields creates a new ref.
+type fieldStackRef struct {
+	mu    sync.RWMutex
+	stack *fieldStack
+}
+
+func (r *fieldStackRef) load() *fieldStack {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.stack
+}
+
+func (r *fieldStackRef) update(fn func(*fieldStack) *fieldStack) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stack = fn(r.stack)
+}
+
 // WithContext initializes context with a logging fields stack with the given fields. If the given
-// context has already bene initialized, then the fields are pushed onto the existing stack.
+// context has already been initialized, then the fields are pushed onto the existing stack in
+// place and parent is returned.
 func WithContext(parent context.Context, fields ...Field) context.Context {
-	if parent.Value(ContextKeyLogFields) != nil {
-		PushContextFields(parent, fields...)
+	if ref, ok := parent.Value(ContextKeyLogFields).(*fieldStackRef); ok {
+		ref.update(func(s *fieldStack) *fieldStack {
+			return s.push(fields)
+		})
 		return parent
 	}
-	return context.WithValue(parent, ContextKeyLogFields, makeFieldStack().push(fields))
+	return ContextWithFields(parent, fields...)
+}
+
+// ContextWithFields returns a context derived from parent with the given fields pushed onto
+// its own copy of the logging fields stack. Neither the fields nor later pushes and pops on
+// the returned context are seen by parent, so goroutines forked from a shared context should
+// use it to add their fields.
+func ContextWithFields(parent context.Context, fields ...Field) context.Context {
+	stack := makeFieldStack()
+	if ref, ok := parent.Value(ContextKeyLogFields).(*fieldStackRef); ok {
+		stack = ref.load()
+	}
+	return context.WithValue(parent, ContextKeyLogFields, &fieldStackRef{stack: stack.push(fields)})
 }
 
-// PushContextFields pushes the given fields onto the logging fields stack.
+// PushContextFields pushes the given fields onto the logging fields stack in place. The
+// fields are seen by every holder of ctx until they are popped.
 func PushContextFields(ctx context.Context, fields ...Field) {
-	stack := getStack(ctx)
-	if stack == nil {
+	ref := getStackRef(ctx)
+	if ref == nil {
 		return
 	}
-	stack.push(fields)
+	ref.update(func(s *fieldStack) *fieldStack {
+		return s.push(fields)
+	})
 }
 
-// PopContextFields pops the last entry off of the logging fields stack.
+// PopContextFields pops the last entry off of the logging fields stack in place.
 func PopContextFields(ctx context.Context) {
-	stack := getStack(ctx)
-	if stack == nil {
+	ref := getStackRef(ctx)
+	if ref == nil {
 		return
 	}
-	stack.pop()
+	ref.update(func(s *fieldStack) *fieldStack {
+		return s.pop()
+	})
 }
 
 // GetContextFields retrieves the logging `Fields` from context. GetContextFields returns an empty Fields map
@@ -49,15 +94,37 @@ func GetContextFields(ctx context.Context, additionalFields ...Field) Fields {
 	return fields
 }
 
+// contextFields returns the logging fields of ctx along with the chi request ID and the
+// OpenTelemetry span IDs, without warning when ctx has not been initialized.
+func contextFields(ctx context.Context) Fields {
+	fields := make(Fields)
+	if ref, ok := ctx.Value(ContextKeyLogFields).(*fieldStackRef); ok {
+		fields = ref.load().allFields()
+	}
+	if requestID := middleware.GetReqID(ctx); requestID != "" {
+		fields["requestID"] = requestID
+	}
+	addTraceFields(ctx, fields)
+	return fields
+}
+
 func getStack(ctx context.Context) *fieldStack {
+	ref := getStackRef(ctx)
+	if ref == nil {
+		return nil
+	}
+	return ref.load()
+}
+
+func getStackRef(ctx context.Context) *fieldStackRef {
 	stackObj := ctx.Value(ContextKeyLogFields)
 	if stackObj == nil {
 		Warn("context logging fields not initialized; call log.WithContext")
 		return nil
 	}
-	stack, ok := stackObj.(*fieldStack)
+	ref, ok := stackObj.(*fieldStackRef)
 	if !ok {
 		Warn("context logging fields has incorrect type")
 	}
-	return stack
+	return ref
 }
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// ContextHook is a logrus.Hook that adds the logging fields pushed with WithContext, along
+// with the chi request ID, to entries carrying a context, so that
+// logger.WithContext(ctx).Info(...) includes them. Fields set on the entry take precedence.
+// Loggers built with New or NewLogger have it installed.
+type ContextHook struct{}
+
+var _ logrus.Hook = ContextHook{}
+
+func (ContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (ContextHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	fields := contextFields(entry.Context)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// entry.Data may be shared with the entry the log call was made on, so it is replaced
+	// rather than modified.
+	data := make(logrus.Fields, len(entry.Data)+len(fields))
+	for name, value := range fields {
+		data[name] = value
+	}
+	for name, value := range entry.Data {
+		data[name] = value
+	}
+	entry.Data = data
+	return nil
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/go-chi/chi/middleware"
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("ContextHook", func() {
+	var (
+		buf    bytes.Buffer
+		logger *logrus.Logger
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		logger = NewLogger(WithJSONFormat(), WithOutput(&buf))
+		ctx = context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+		ctx = WithContext(ctx, MakeField("user", "u1"))
+	})
+
+	parse := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	It("should add context fields to entries carrying a context", func() {
+		logger.WithContext(ctx).WithField("user", "explicit").Info("with context")
+		logger.WithContext(WithContext(ctx, MakeField("step", 2))).Info("nested")
+
+		logs := parse()
+		g.Expect(logs[0]["requestID"]).To(g.Equal("req-1"))
+		g.Expect(logs[0]["user"]).To(g.Equal("explicit"))
+		g.Expect(logs[1]["user"]).To(g.Equal("u1"))
+		g.Expect(logs[1]["step"]).To(g.BeNumerically("==", 2))
+	})
+
+	It("should not change entries without a context", func() {
+		logger.Info("plain")
+
+		logs := parse()
+		g.Expect(logs[0]).ToNot(g.HaveKey("requestID"))
+		g.Expect(logs[0]).ToNot(g.HaveKey("user"))
+	})
+
+	It("should not modify the fields of a shared entry", func() {
+		entry := logger.WithContext(ctx).WithField("shared", true)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				entry.Info("concurrent")
+			}()
+		}
+		wg.Wait()
+
+		g.Expect(entry.Data).To(g.Equal(logrus.Fields{"shared": true}))
+	})
+})
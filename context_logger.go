@@ -0,0 +1,63 @@
+package log
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FromContext returns a logger built on the default logger carrying the logging fields of
+// ctx and the chi request ID, with ctx attached to every entry. The fields are set here
+// rather than left to ContextHook, since a logger passed to SetDefault may not have it
+// installed.
+func FromContext(ctx context.Context) FieldLogger {
+	return NewFieldLogger(Default().WithFields(logrus.Fields(contextFields(ctx))).WithContext(ctx))
+}
+
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Info(args...)
+}
+
+func InfoCtxf(ctx context.Context, message string, args ...interface{}) {
+	FromContext(ctx).Infof(message, args...)
+}
+
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Debug(args...)
+}
+
+func DebugCtxf(ctx context.Context, message string, args ...interface{}) {
+	FromContext(ctx).Debugf(message, args...)
+}
+
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Error(args...)
+}
+
+func ErrorCtxf(ctx context.Context, message string, args ...interface{}) {
+	FromContext(ctx).Errorf(message, args...)
+}
+
+func WarnCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Warn(args...)
+}
+
+func WarnCtxf(ctx context.Context, message string, args ...interface{}) {
+	FromContext(ctx).Warnf(message, args...)
+}
+
+func FatalCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Fatal(args...)
+}
+
+func FatalCtxf(ctx context.Context, message string, args ...interface{}) {
+	FromContext(ctx).Fatalf(message, args...)
+}
+
+func PanicCtx(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).Panic(args...)
+}
+
+func PanicCtxf(ctx context.Context, message string, args ...interface{}) {
+	FromContext(ctx).Panicf(message, args...)
+}
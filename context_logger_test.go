@@ -0,0 +1,107 @@
+package log
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/go-chi/chi/middleware"
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Context logging", func() {
+	var (
+		buf      bytes.Buffer
+		logger   *logrus.Logger
+		previous *logrus.Logger
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		logger = NewLogger(WithJSONFormat(), WithOutput(&buf))
+		previous = Default()
+		SetDefault(logger)
+
+		ctx = context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+		ctx = WithContext(ctx, MakeField("user", "u1"))
+	})
+
+	AfterEach(func() {
+		SetDefault(previous)
+	})
+
+	parse := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	It("should include context fields and the request ID in package level functions", func() {
+		InfoCtx(ctx, "served")
+		ErrorCtxf(ctx, "failed %d", 1)
+
+		logs := parse()
+		g.Expect(logs).To(g.HaveLen(2))
+		for _, entry := range logs {
+			g.Expect(entry["user"]).To(g.Equal("u1"))
+			g.Expect(entry["requestID"]).To(g.Equal("req-1"))
+		}
+		g.Expect(logs[1]["msg"]).To(g.Equal("failed 1"))
+		g.Expect(logs[1]["level"]).To(g.Equal("error"))
+	})
+
+	It("should not warn for uninitialized contexts", func() {
+		WarnCtx(context.Background(), "plain")
+
+		logs := parse()
+		g.Expect(logs).To(g.HaveLen(1))
+		g.Expect(logs[0]).ToNot(g.HaveKey("requestID"))
+	})
+
+	It("should return a logger populated from the context", func() {
+		l := FromContext(ctx)
+
+		l.WithField("extra", true).Info("from context")
+
+		logs := parse()
+		g.Expect(logs[0]["user"]).To(g.Equal("u1"))
+		g.Expect(logs[0]["requestID"]).To(g.Equal("req-1"))
+		g.Expect(logs[0]["extra"]).To(g.Equal(true))
+	})
+
+	It("should include context fields with a default logger without ContextHook", func() {
+		plain := logrus.New()
+		plain.SetFormatter(&logrus.JSONFormatter{})
+		plain.SetOutput(&buf)
+		SetDefault(plain)
+
+		pl := NewPrefixedLogger("worker", plain)
+
+		InfoCtx(ctx, "served")
+		pl.InfoCtx(ctx, "started")
+
+		logs := parse()
+		g.Expect(logs).To(g.HaveLen(2))
+		for _, entry := range logs {
+			g.Expect(entry["user"]).To(g.Equal("u1"))
+			g.Expect(entry["requestID"]).To(g.Equal("req-1"))
+		}
+	})
+
+	It("should include context fields in PrefixedLogger", func() {
+		pl := NewPrefixedLogger("worker", logger)
+		child := pl.WithField("user", "override").(*PrefixedLogger)
+
+		pl.InfoCtx(ctx, "started")
+		child.WarnCtxf(ctx, "retry %d", 2)
+
+		logs := parse()
+		g.Expect(logs[0]["msg"]).To(g.Equal("worker: started"))
+		g.Expect(logs[0]["user"]).To(g.Equal("u1"))
+		g.Expect(logs[0]["requestID"]).To(g.Equal("req-1"))
+		g.Expect(logs[1]["msg"]).To(g.Equal("worker: retry 2"))
+		g.Expect(logs[1]["user"]).To(g.Equal("override"))
+	})
+})
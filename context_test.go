@@ -2,6 +2,8 @@ package log
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	. "github.com/onsi/ginkgo"
 	g "github.com/onsi/gomega" // gomega.Panic function collides with log.Panic
@@ -52,6 +54,71 @@ var _ = Describe("Context", func() {
 			PopContextFields(ctx)
 			PopContextFields(ctx)
 		})
+
+		It("should push onto an initialized context in place", func() {
+			ctx := WithContext(context.Background(), MakeField("foo", 5))
+
+			g.Expect(WithContext(ctx, MakeField("bar", 6))).To(g.BeIdenticalTo(ctx))
+			g.Expect(GetContextFields(ctx)).To(g.Equal(Fields{"foo": 5, "bar": 6}))
+		})
+
+		It("should derive a context without changing the parent", func() {
+			parent := WithContext(context.Background(), MakeField("foo", 5))
+			child := ContextWithFields(parent, MakeField("bar", 6))
+			PushContextFields(child, MakeField("taz", 7))
+			PushContextFields(parent, MakeField("qux", 8))
+
+			g.Expect(GetContextFields(parent)).To(g.Equal(Fields{"foo": 5, "qux": 8}))
+			g.Expect(GetContextFields(child)).To(g.Equal(Fields{"foo": 5, "bar": 6, "taz": 7}))
+		})
+
+		It("should initialize the stack when deriving a context", func() {
+			ctx := ContextWithFields(context.Background(), MakeField("foo", 5))
+
+			g.Expect(GetContextFields(ctx)).To(g.Equal(Fields{"foo": 5}))
+		})
+
+		It("should isolate goroutines forked from the same context", func() {
+			parent := WithContext(context.Background(), MakeField("request", "r1"))
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					ctx := ContextWithFields(parent, MakeField("worker", i))
+					PushContextFields(ctx, MakeField(fmt.Sprintf("step%d", i), true))
+
+					fields := GetContextFields(ctx)
+					g.Expect(fields).To(g.HaveLen(3))
+					g.Expect(fields["request"]).To(g.Equal("r1"))
+					g.Expect(fields["worker"]).To(g.Equal(i))
+				}(i)
+			}
+			wg.Wait()
+
+			g.Expect(GetContextFields(parent)).To(g.Equal(Fields{"request": "r1"}))
+		})
+
+		It("should allow concurrent push and pop on a shared context", func() {
+			ctx := WithContext(context.Background(), MakeField("foo", 5))
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					PushContextFields(ctx, MakeField("bar", i))
+					GetContextFields(ctx)
+					PopContextFields(ctx)
+				}(i)
+			}
+			wg.Wait()
+
+			g.Expect(GetContextFields(ctx)).To(g.Equal(Fields{"foo": 5}))
+		})
 	})
 })
 
@@ -0,0 +1,141 @@
+package log
+
+import (
+	"context"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logrusEntryMaker is implemented by both *logrus.Logger and *logrus.Entry.
+type logrusEntryMaker interface {
+	WithFields(fields logrus.Fields) *logrus.Entry
+}
+
+type entryLogger struct {
+	entry *logrus.Entry
+}
+
+var _ FieldLogger = (*entryLogger)(nil)
+
+// NewFieldLogger wraps a *logrus.Logger or *logrus.Entry in a FieldLogger. Fields already
+// set on an entry are kept.
+func NewFieldLogger(l logrusEntryMaker) FieldLogger {
+	return &entryLogger{entry: l.WithFields(nil)}
+}
+
+func (l *entryLogger) WithField(key string, value interface{}) FieldLogger {
+	return &entryLogger{entry: l.entry.WithField(key, value)}
+}
+
+func (l *entryLogger) WithFields(fields Fields) FieldLogger {
+	return &entryLogger{entry: l.entry.WithFields(logrus.Fields(fields))}
+}
+
+func (l *entryLogger) WithError(err error) FieldLogger {
+	return &entryLogger{entry: l.entry.WithError(err)}
+}
+
+func (l *entryLogger) WithContext(ctx context.Context) FieldLogger {
+	return &entryLogger{entry: l.entry.WithContext(ctx)}
+}
+
+func (l *entryLogger) Info(args ...interface{}) {
+	l.entry.Info(args...)
+}
+
+func (l *entryLogger) Infof(message string, args ...interface{}) {
+	l.entry.Infof(message, args...)
+}
+
+func (l *entryLogger) Debug(args ...interface{}) {
+	l.entry.Debug(args...)
+}
+
+func (l *entryLogger) Debugf(message string, args ...interface{}) {
+	l.entry.Debugf(message, args...)
+}
+
+func (l *entryLogger) Error(args ...interface{}) {
+	l.entry.Error(args...)
+}
+
+func (l *entryLogger) Errorf(message string, args ...interface{}) {
+	l.entry.Errorf(message, args...)
+}
+
+func (l *entryLogger) Warn(args ...interface{}) {
+	l.entry.Warn(args...)
+}
+
+func (l *entryLogger) Warnf(message string, args ...interface{}) {
+	l.entry.Warnf(message, args...)
+}
+
+func (l *entryLogger) Fatal(args ...interface{}) {
+	l.entry.Fatal(args...)
+}
+
+func (l *entryLogger) Fatalf(message string, args ...interface{}) {
+	l.entry.Fatalf(message, args...)
+}
+
+func (l *entryLogger) Panic(args ...interface{}) {
+	l.entry.Panic(args...)
+}
+
+func (l *entryLogger) Panicf(message string, args ...interface{}) {
+	l.entry.Panicf(message, args...)
+}
+
+func (l *entryLogger) Writer() *io.PipeWriter {
+	return l.entry.Writer()
+}
+
+func (l *entryLogger) InfoWithFields(fields Fields, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Info(args...)
+}
+
+func (l *entryLogger) InfoWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Infof(message, args...)
+}
+
+func (l *entryLogger) DebugWithFields(fields Fields, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Debug(args...)
+}
+
+func (l *entryLogger) DebugWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Debugf(message, args...)
+}
+
+func (l *entryLogger) ErrorWithFields(fields Fields, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Error(args...)
+}
+
+func (l *entryLogger) ErrorWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Errorf(message, args...)
+}
+
+func (l *entryLogger) WarnWithFields(fields Fields, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Warn(args...)
+}
+
+func (l *entryLogger) WarnWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Warnf(message, args...)
+}
+
+func (l *entryLogger) FatalWithFields(fields Fields, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Fatal(args...)
+}
+
+func (l *entryLogger) FatalWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Fatalf(message, args...)
+}
+
+func (l *entryLogger) PanicWithFields(fields Fields, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Panic(args...)
+}
+
+func (l *entryLogger) PanicWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.entry.WithFields(logrus.Fields(fields)).Panicf(message, args...)
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("FieldLogger", func() {
+	var (
+		buf    bytes.Buffer
+		logger *logrus.Logger
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		logger = NewLogger(WithJSONFormat(), WithOutput(&buf))
+	})
+
+	parse := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	It("should add fields and errors through the interface", func() {
+		var l FieldLogger = NewFieldLogger(logger)
+
+		l.WithField("a", "1").WithFields(Fields{"b": "2"}).WithError(errors.New("boom")).Info("hello")
+
+		logs := parse()
+		g.Expect(logs).To(g.HaveLen(1))
+		g.Expect(logs[0]["msg"]).To(g.Equal("hello"))
+		g.Expect(logs[0]["a"]).To(g.Equal("1"))
+		g.Expect(logs[0]["b"]).To(g.Equal("2"))
+		g.Expect(logs[0]["error"]).To(g.Equal("boom"))
+	})
+
+	It("should keep the fields of a wrapped entry", func() {
+		l := NewFieldLogger(logger.WithField("requestID", "abc"))
+
+		l.WarnWithFieldsf(Fields{"attempt": 2}, "retrying %s", "job")
+
+		logs := parse()
+		g.Expect(logs[0]["msg"]).To(g.Equal("retrying job"))
+		g.Expect(logs[0]["requestID"]).To(g.Equal("abc"))
+		g.Expect(logs[0]["attempt"]).To(g.BeNumerically("==", 2))
+	})
+
+	It("should attach the context to entries", func() {
+		hook := &contextHook{}
+		logger.AddHook(hook)
+		ctx := context.WithValue(context.Background(), ContextKeyLogFields, "marker")
+
+		NewFieldLogger(logger).WithContext(ctx).Info("with context")
+
+		g.Expect(hook.ctx).To(g.Equal(ctx))
+	})
+
+	Describe("PrefixedLogger", func() {
+		It("should implement FieldLogger", func() {
+			pl := NewPrefixedLogger("worker", logger)
+			var l FieldLogger = &pl
+
+			child := l.WithFields(Fields{"job": "sync"})
+			child.InfoWithFields(Fields{"step": 1}, "started")
+			l.Info("parent")
+
+			logs := parse()
+			g.Expect(logs).To(g.HaveLen(2))
+			g.Expect(logs[0]["msg"]).To(g.Equal("worker: started"))
+			g.Expect(logs[0]["job"]).To(g.Equal("sync"))
+			g.Expect(logs[0]["step"]).To(g.BeNumerically("==", 1))
+			g.Expect(logs[1]).ToNot(g.HaveKey("job"))
+		})
+
+		It("should attach errors and context", func() {
+			hook := &contextHook{}
+			logger.AddHook(hook)
+			ctx := context.WithValue(context.Background(), ContextKeyLogFields, "marker")
+			pl := NewPrefixedLogger("worker", logger)
+
+			pl.WithContext(ctx).WithError(errors.New("boom")).Errorf("failed %d", 1)
+
+			logs := parse()
+			g.Expect(logs[0]["msg"]).To(g.Equal("worker: failed 1"))
+			g.Expect(logs[0]["error"]).To(g.Equal("boom"))
+			g.Expect(hook.ctx).To(g.Equal(ctx))
+		})
+	})
+})
+
+type contextHook struct {
+	ctx context.Context
+}
+
+func (h *contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *contextHook) Fire(e *logrus.Entry) error {
+	h.ctx = e.Context
+	return nil
+}
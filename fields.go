@@ -1,14 +1,23 @@
 package log
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // Field represents a logging field.
 type Field struct {
-	Name string
+	Name  string
 	Value interface{}
 }
 
 type fieldStackItem struct {
 	fields []Field
 }
+
+// fieldStack is immutable; push and pop return a new stack and leave the receiver intact,
+// so a stack can be shared freely between goroutines.
 type fieldStack struct {
 	items []*fieldStackItem
 }
@@ -34,15 +43,16 @@ func makeFieldStack() *fieldStack {
 }
 
 func (s *fieldStack) push(fields []Field) *fieldStack {
-	item := makeFieldStackItem(fields)
-	s.items = append(s.items, item)
-	return s
+	items := make([]*fieldStackItem, len(s.items), len(s.items)+1)
+	copy(items, s.items)
+	return &fieldStack{items: append(items, makeFieldStackItem(fields))}
 }
 
-func (s *fieldStack) pop() {
-	if len(s.items) > 0 {
-		s.items = s.items[:len(s.items)-1]
+func (s *fieldStack) pop() *fieldStack {
+	if len(s.items) == 0 {
+		return s
 	}
+	return &fieldStack{items: s.items[:len(s.items)-1]}
 }
 
 func (s *fieldStack) allFields() Fields {
@@ -54,3 +64,30 @@ func (s *fieldStack) allFields() Fields {
 	}
 	return fields
 }
+
+// mergeFields returns a new Fields with the entries of b overriding those of a.
+func mergeFields(a, b Fields) Fields {
+	fields := make(Fields, len(a)+len(b))
+	for name, value := range a {
+		fields[name] = value
+	}
+	for name, value := range b {
+		fields[name] = value
+	}
+	return fields
+}
+
+// formatFields renders fields as space separated key=value pairs sorted by key.
+func formatFields(fields Fields) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%v", name, fields[name])
+	}
+	return strings.Join(pairs, " ")
+}
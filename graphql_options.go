@@ -0,0 +1,224 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// GraphQLOption configures the request logging of NewSLogGraphQLResponseMiddleware.
+type GraphQLOption func(*graphqlOptions)
+
+type graphqlOptions struct {
+	skipOperations       map[string]struct{}
+	skipTypes            map[ast.Operation]struct{}
+	skipFuncs            []func(*graphql.OperationContext) bool
+	logServiceDefinition bool
+	sampleRate           uint64
+	sampleRates          map[string]uint64
+	sampleCount          atomic.Uint64
+	sampleCounts         sync.Map
+	signature            bool
+	omitQuery            bool
+	maxQueryLength       int
+}
+
+func newGraphQLOptions(opts []GraphQLOption) *graphqlOptions {
+	o := &graphqlOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !o.logServiceDefinition {
+		o.skipFuncs = append(o.skipFuncs, isServiceDefinitionQuery)
+	}
+	return o
+}
+
+// WithGraphQLSkipOperations skips logging operations with one of the given names.
+func WithGraphQLSkipOperations(names ...string) GraphQLOption {
+	return func(o *graphqlOptions) {
+		if o.skipOperations == nil {
+			o.skipOperations = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			o.skipOperations[name] = struct{}{}
+		}
+	}
+}
+
+// WithGraphQLSkipOperationTypes skips logging operations of the given types, such as
+// ast.Subscription.
+func WithGraphQLSkipOperationTypes(types ...ast.Operation) GraphQLOption {
+	return func(o *graphqlOptions) {
+		if o.skipTypes == nil {
+			o.skipTypes = make(map[ast.Operation]struct{}, len(types))
+		}
+		for _, t := range types {
+			o.skipTypes[t] = struct{}{}
+		}
+	}
+}
+
+// WithGraphQLSkipIntrospection skips logging introspection operations, whose top level
+// fields are all schema introspection fields or the federation _service field.
+func WithGraphQLSkipIntrospection() GraphQLOption {
+	return WithGraphQLSkipFunc(isIntrospectionQuery)
+}
+
+// WithGraphQLLogServiceDefinition logs the service definition queries Apollo gateways
+// poll the schema with, which are skipped by default.
+func WithGraphQLLogServiceDefinition() GraphQLOption {
+	return func(o *graphqlOptions) {
+		o.logServiceDefinition = true
+	}
+}
+
+// WithGraphQLSkipFunc skips logging operations for which skip returns true.
+func WithGraphQLSkipFunc(skip func(*graphql.OperationContext) bool) GraphQLOption {
+	return func(o *graphqlOptions) {
+		o.skipFuncs = append(o.skipFuncs, skip)
+	}
+}
+
+// WithGraphQLSampling logs only one in n responses without errors. Operations not
+// configured with WithGraphQLOperationSampling are counted together. Logged responses
+// without errors carry a sampleRate field.
+func WithGraphQLSampling(n int) GraphQLOption {
+	return func(o *graphqlOptions) {
+		o.sampleRate = sampleRate(n)
+	}
+}
+
+// WithGraphQLOperationSampling overrides the sampling rate of WithGraphQLSampling for the
+// named operation, which gets its own counter. A rate of 1 logs every response.
+func WithGraphQLOperationSampling(name string, n int) GraphQLOption {
+	return func(o *graphqlOptions) {
+		if o.sampleRates == nil {
+			o.sampleRates = make(map[string]uint64)
+		}
+		o.sampleRates[name] = sampleRate(n)
+	}
+}
+
+// WithGraphQLQuerySignature logs the signature of the query, as returned by
+// NormalizeQuery, and its hash for grouping operations regardless of their inline values.
+func WithGraphQLQuerySignature() GraphQLOption {
+	return func(o *graphqlOptions) {
+		o.signature = true
+	}
+}
+
+// WithGraphQLOmitQuery leaves the raw query out of the log entries, for queries that may
+// hold personal data in their inline values. Combine it with WithGraphQLQuerySignature to
+// still log the signature and its hash.
+func WithGraphQLOmitQuery() GraphQLOption {
+	return func(o *graphqlOptions) {
+		o.omitQuery = true
+	}
+}
+
+// WithGraphQLMaxQueryLength truncates the logged raw query to n bytes and marks it with a
+// queryTruncated field.
+func WithGraphQLMaxQueryLength(n int) GraphQLOption {
+	return func(o *graphqlOptions) {
+		o.maxQueryLength = n
+	}
+}
+
+func sampleRate(n int) uint64 {
+	if n > 1 {
+		return uint64(n)
+	}
+	return 0
+}
+
+// skip reports whether the operation matches a skip rule.
+func (o *graphqlOptions) skip(oc *graphql.OperationContext) bool {
+	if _, ok := o.skipOperations[operationName(oc)]; ok {
+		return true
+	}
+	if oc.Operation != nil {
+		if _, ok := o.skipTypes[oc.Operation.Operation]; ok {
+			return true
+		}
+	}
+	for _, skip := range o.skipFuncs {
+		if skip(oc) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *graphqlOptions) hasOperationRate(name string) bool {
+	_, ok := o.sampleRates[name]
+	return ok
+}
+
+// rate returns the sampling rate of the response, 0 when it is not sampled. Responses with
+// errors are never sampled.
+func (o *graphqlOptions) rate(oc *graphql.OperationContext, res *graphql.Response) uint64 {
+	if res != nil && len(res.Errors) > 0 {
+		return 0
+	}
+	if rate, ok := o.sampleRates[operationName(oc)]; ok {
+		return rate
+	}
+	return o.sampleRate
+}
+
+// sample reports whether a response should be logged under the sampling rules.
+func (o *graphqlOptions) sample(oc *graphql.OperationContext, res *graphql.Response) bool {
+	rate := o.rate(oc, res)
+	if rate == 0 {
+		return true
+	}
+
+	// Operation names are chosen by clients, so only the configured ones get a counter.
+	count := &o.sampleCount
+	if name := operationName(oc); o.hasOperationRate(name) {
+		c, _ := o.sampleCounts.LoadOrStore(name, new(atomic.Uint64))
+		count = c.(*atomic.Uint64)
+	}
+	return (count.Add(1)-1)%rate == 0
+}
+
+// isServiceDefinitionQuery matches the schema polls of Apollo gateways.
+func isServiceDefinitionQuery(oc *graphql.OperationContext) bool {
+	return strings.Contains(oc.RawQuery, "__ApolloGetServiceDefinition__")
+}
+
+func isIntrospectionQuery(oc *graphql.OperationContext) bool {
+	if oc.Operation == nil {
+		return false
+	}
+	return isIntrospectionSelection(oc.Operation.SelectionSet)
+}
+
+func isIntrospectionSelection(set ast.SelectionSet) bool {
+	if len(set) == 0 {
+		return false
+	}
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if !strings.HasPrefix(s.Name, "__") && s.Name != "_service" {
+				return false
+			}
+		case *ast.InlineFragment:
+			if !isIntrospectionSelection(s.SelectionSet) {
+				return false
+			}
+		case *ast.FragmentSpread:
+			if s.Definition == nil || !isIntrospectionSelection(s.Definition.SelectionSet) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
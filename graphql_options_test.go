@@ -0,0 +1,137 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+var _ = Describe("GraphQL options", func() {
+	var (
+		buf bytes.Buffer
+		l   *slog.Logger
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		l = slog.New(slog.NewJSONHandler(&buf, nil))
+	})
+
+	operation := func(name string, op ast.Operation, fields ...string) *graphql.OperationContext {
+		set := make(ast.SelectionSet, len(fields))
+		for i, field := range fields {
+			set[i] = &ast.Field{Name: field, Alias: field}
+		}
+		return &graphql.OperationContext{
+			RawQuery:      "query",
+			OperationName: name,
+			Operation:     &ast.OperationDefinition{Operation: op, Name: name, SelectionSet: set},
+		}
+	}
+
+	ok := func(context.Context) *graphql.Response {
+		return &graphql.Response{}
+	}
+
+	serve := func(mw graphql.ResponseMiddleware, oc *graphql.OperationContext, handler graphql.ResponseHandler) {
+		mw(graphql.WithOperationContext(context.Background(), oc), handler)
+	}
+
+	lines := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	It("should skip Apollo service definition queries by default", func() {
+		mw := NewSLogGraphQLResponseMiddleware(l, nil)
+
+		oc := operation("__ApolloGetServiceDefinition__", ast.Query, "_service")
+		oc.RawQuery = "query __ApolloGetServiceDefinition__ { _service { sdl } }"
+		serve(mw, oc, ok)
+		serve(mw, operation("Users", ast.Query, "users"), ok)
+
+		logs := lines()
+		g.Expect(logs).To(g.HaveLen(1))
+		g.Expect(logs[0]["graphql"]).To(g.HaveKeyWithValue("req", g.HaveKeyWithValue("operationName", "Users")))
+	})
+
+	It("should log Apollo service definition queries when enabled", func() {
+		mw := NewSLogGraphQLResponseMiddleware(l, nil, WithGraphQLLogServiceDefinition())
+
+		oc := operation("__ApolloGetServiceDefinition__", ast.Query, "_service")
+		oc.RawQuery = "query __ApolloGetServiceDefinition__ { _service { sdl } }"
+		serve(mw, oc, ok)
+
+		g.Expect(lines()).To(g.HaveLen(1))
+	})
+
+	It("should skip operations by name and type", func() {
+		mw := NewSLogGraphQLResponseMiddleware(l, nil,
+			WithGraphQLSkipOperations("Health"),
+			WithGraphQLSkipOperationTypes(ast.Subscription),
+		)
+
+		serve(mw, operation("Health", ast.Query, "health"), ok)
+		serve(mw, operation("OnUser", ast.Subscription, "user"), ok)
+		serve(mw, operation("Users", ast.Query, "users"), ok)
+
+		g.Expect(lines()).To(g.HaveLen(1))
+	})
+
+	It("should skip introspection queries", func() {
+		mw := NewSLogGraphQLResponseMiddleware(l, nil, WithGraphQLSkipIntrospection())
+
+		serve(mw, operation("IntrospectionQuery", ast.Query, "__schema"), ok)
+		serve(mw, operation("", ast.Query, "__typename"), ok)
+		serve(mw, operation("Mixed", ast.Query, "__typename", "users"), ok)
+
+		logs := lines()
+		g.Expect(logs).To(g.HaveLen(1))
+		g.Expect(logs[0]["graphql"]).To(g.HaveKeyWithValue("req", g.HaveKeyWithValue("operationName", "Mixed")))
+	})
+
+	It("should skip operations matching a skip func", func() {
+		mw := NewSLogGraphQLResponseMiddleware(l, nil,
+			WithGraphQLSkipFunc(func(oc *graphql.OperationContext) bool { return oc.OperationName == "Ping" }),
+		)
+
+		serve(mw, operation("Ping", ast.Query, "ping"), ok)
+
+		g.Expect(buf.Len()).To(g.BeZero())
+	})
+
+	It("should sample configured operations separately and always log errors", func() {
+		mw := NewSLogGraphQLResponseMiddleware(l, nil,
+			WithGraphQLSampling(3),
+			WithGraphQLOperationSampling("CreateUser", 1),
+		)
+		failed := func(context.Context) *graphql.Response {
+			return &graphql.Response{Errors: gqlerror.List{{Message: "boom"}}}
+		}
+
+		for i := 0; i < 4; i++ {
+			serve(mw, operation("Users", ast.Query, "users"), ok)
+			serve(mw, operation("Orders", ast.Query, "orders"), ok)
+			serve(mw, operation("CreateUser", ast.Mutation, "createUser"), ok)
+		}
+		serve(mw, operation("Users", ast.Query, "users"), failed)
+
+		counts := map[string]int{}
+		for _, line := range lines() {
+			req := line["graphql"].(map[string]interface{})["req"].(map[string]interface{})
+			counts[req["operationName"].(string)]++
+		}
+		g.Expect(counts).To(g.Equal(map[string]int{"Users": 3, "Orders": 1, "CreateUser": 4}))
+		logs := lines()
+		g.Expect(logs[0]["graphql"]).To(g.HaveKeyWithValue("sampleRate", 3.0))
+		g.Expect(logs[len(logs)-1]["graphql"]).To(g.HaveKeyWithValue("res", g.HaveKey("errors")))
+		g.Expect(logs[len(logs)-1]["graphql"]).ToNot(g.HaveKey("sampleRate"))
+	})
+})
@@ -0,0 +1,86 @@
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/lexer"
+)
+
+// NormalizeQuery returns the signature of a GraphQL query: comments are dropped,
+// whitespace is collapsed and literals are replaced, numbers with 0 and strings with "",
+// so that queries differing only in their inline values share a signature. Queries that
+// cannot be tokenized have no signature and yield "", so that their literals are never
+// logged.
+func NormalizeQuery(query string) string {
+	var (
+		b    strings.Builder
+		lex  = lexer.New(&ast.Source{Input: query})
+		prev lexer.Type
+	)
+	for {
+		tok, err := lex.ReadToken()
+		if err != nil {
+			return ""
+		}
+		if tok.Kind == lexer.EOF {
+			return b.String()
+		}
+		if tok.Kind == lexer.Comment {
+			continue
+		}
+
+		if isWordToken(prev) && isWordToken(tok.Kind) {
+			b.WriteByte(' ')
+		}
+		switch tok.Kind {
+		case lexer.Name:
+			b.WriteString(tok.Value)
+		case lexer.Int, lexer.Float:
+			b.WriteString("0")
+		case lexer.String, lexer.BlockString:
+			b.WriteString(`""`)
+		default:
+			b.WriteString(tok.Kind.String())
+		}
+		prev = tok.Kind
+	}
+}
+
+// QuerySignatureHash returns the hex SHA-256 of the signature of query, for grouping
+// operations, or "" when query has no signature.
+func QuerySignatureHash(query string) string {
+	return hashSignature(NormalizeQuery(query))
+}
+
+func hashSignature(signature string) string {
+	if signature == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(signature))
+	return hex.EncodeToString(sum[:])
+}
+
+func isWordToken(kind lexer.Type) bool {
+	switch kind {
+	case lexer.Name, lexer.Int, lexer.Float, lexer.String, lexer.BlockString:
+		return true
+	}
+	return false
+}
+
+// truncateQuery cuts query to at most max bytes on a rune boundary. A max of 0 disables
+// truncation.
+func truncateQuery(query string, max int) (string, bool) {
+	if max <= 0 || len(query) <= max {
+		return query, false
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(query[cut]) {
+		cut--
+	}
+	return query[:cut], true
+}
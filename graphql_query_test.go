@@ -0,0 +1,121 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	g "github.com/onsi/gomega"
+)
+
+var _ = Describe("GraphQL queries", func() {
+	table.DescribeTable("NormalizeQuery",
+		func(query, signature string) {
+			g.Expect(NormalizeQuery(query)).To(g.Equal(signature))
+		},
+		table.Entry("whitespace", "query  Users {\n  users {\n    id\n    name\n  }\n}", "query Users{users{id name}}"),
+		table.Entry("literals",
+			`{ user(id: 42, score: 1.5, email: "jane@example.com", bio: """long""") { id } }`,
+			`{user(id:0 score:0 email:"" bio:""){id}}`),
+		table.Entry("variables and fragments",
+			"query Q($id: ID!) { user(id: $id) { ...F @include(if: true) } } # comment\nfragment F on User { id }",
+			"query Q($id:ID!){user(id:$id){...F@include(if:true)}}fragment F on User{id}"),
+		table.Entry("invalid input", "{ user(id: \"unterminated }\n  ", ""),
+	)
+
+	It("should hash equivalent queries the same", func() {
+		a := QuerySignatureHash(`{ user(email: "a@example.com") { id } }`)
+		b := QuerySignatureHash("{\n  user(email: \"b@example.com\") {\n    id\n  }\n}")
+
+		g.Expect(a).To(g.HaveLen(64))
+		g.Expect(a).To(g.Equal(b))
+		g.Expect(a).ToNot(g.Equal(QuerySignatureHash(`{ user(email: "a@example.com") { name } }`)))
+		g.Expect(QuerySignatureHash(`{ user(email: "a@example.com`)).To(g.BeEmpty())
+	})
+
+	It("should truncate on a rune boundary", func() {
+		query, truncated := truncateQuery("héllo", 2)
+		g.Expect(query).To(g.Equal("h"))
+		g.Expect(truncated).To(g.BeTrue())
+
+		query, _ = truncateQuery("héllo", 3)
+		g.Expect(query).To(g.Equal("hé"))
+
+		query, truncated = truncateQuery("hello", 0)
+		g.Expect(query).To(g.Equal("hello"))
+		g.Expect(truncated).To(g.BeFalse())
+	})
+
+	Describe("NewSLogGraphQLResponseMiddleware", func() {
+		var (
+			buf bytes.Buffer
+			l   *slog.Logger
+		)
+
+		BeforeEach(func() {
+			buf = bytes.Buffer{}
+			l = slog.New(slog.NewJSONHandler(&buf, nil))
+		})
+
+		serve := func(mw graphql.ResponseMiddleware, oc *graphql.OperationContext) map[string]interface{} {
+			mw(graphql.WithOperationContext(context.Background(), oc), func(context.Context) *graphql.Response {
+				return &graphql.Response{}
+			})
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed).To(g.HaveLen(1))
+			return b.Parsed[0]["graphql"].(map[string]interface{})["req"].(map[string]interface{})
+		}
+
+		It("should log the query signature and truncate the raw query", func() {
+			mw := NewSLogGraphQLResponseMiddleware(l, nil, WithGraphQLQuerySignature(), WithGraphQLMaxQueryLength(10))
+			query := `{ user(email: "jane@example.com") { id } }`
+
+			req := serve(mw, &graphql.OperationContext{RawQuery: query})
+
+			g.Expect(req["query"]).To(g.Equal(`{ user(ema`))
+			g.Expect(req["queryTruncated"]).To(g.Equal(true))
+			g.Expect(req["signature"]).To(g.Equal(`{user(email:""){id}}`))
+			g.Expect(req["signatureHash"]).To(g.Equal(QuerySignatureHash(query)))
+			g.Expect(req).ToNot(g.HaveKey("persistedQueryHash"))
+		})
+
+		It("should omit the raw query", func() {
+			mw := NewSLogGraphQLResponseMiddleware(l, nil, WithGraphQLOmitQuery(), WithGraphQLQuerySignature())
+
+			req := serve(mw, &graphql.OperationContext{RawQuery: `{ user(email: "jane@example.com") { id } }`})
+
+			g.Expect(req).ToNot(g.HaveKey("query"))
+			g.Expect(req["signature"]).To(g.Equal(`{user(email:""){id}}`))
+			g.Expect(req["signatureHash"]).To(g.HaveLen(64))
+		})
+
+		It("should not log a signature for invalid queries", func() {
+			mw := NewSLogGraphQLResponseMiddleware(l, nil, WithGraphQLOmitQuery(), WithGraphQLQuerySignature())
+
+			req := serve(mw, &graphql.OperationContext{RawQuery: `{ user(email: "jane@example.com`})
+
+			g.Expect(req).ToNot(g.HaveKey("query"))
+			g.Expect(req).ToNot(g.HaveKey("signature"))
+			g.Expect(req).ToNot(g.HaveKey("signatureHash"))
+		})
+
+		It("should log the persisted query hash", func() {
+			mw := NewSLogGraphQLResponseMiddleware(l, nil)
+			oc := &graphql.OperationContext{RawQuery: "{ users { id } }"}
+			oc.Stats.SetExtension("APQ", &extension.ApqStats{Hash: "abc123"})
+
+			req := serve(mw, oc)
+
+			g.Expect(req["persistedQueryHash"]).To(g.Equal("abc123"))
+			g.Expect(req["query"]).To(g.Equal("{ users { id } }"))
+			g.Expect(req).ToNot(g.HaveKey("queryTruncated"))
+			g.Expect(req).ToNot(g.HaveKey("signature"))
+		})
+	})
+})
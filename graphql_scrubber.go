@@ -0,0 +1,237 @@
+package log
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ScrubAction is what a RuleScrubber does with a value matched by one of its rules.
+type ScrubAction int
+
+const (
+	// ScrubMask replaces the value with "REDACTED".
+	ScrubMask ScrubAction = iota
+	// ScrubHash replaces the value with a hash, so equal values can still be correlated.
+	ScrubHash
+)
+
+// DefaultScrubKeyPattern matches variable names that usually hold secrets or personal
+// data.
+var DefaultScrubKeyPattern = regexp.MustCompile(`(?i)passw(or)?d|secret|token|api_?key|authorization|ssn|email`)
+
+var _ VariablesScrubber = (*RuleScrubber)(nil)
+
+// RuleScrubber is a VariablesScrubber that masks or hashes GraphQL variables matched by
+// path or key name rules. Maps and slices are walked and copied, so the logged variables
+// keep their shape and the originals are left untouched.
+//
+// Paths are dot-separated variable names from the root of the variables, such as
+// "input.user.password". Slice elements are addressed by their index and "*" matches any
+// name or index, as in "input.users.*.email".
+type RuleScrubber struct {
+	rules         []scrubRule
+	allow         [][]string
+	allowlist     bool
+	hashKey       []byte
+	noDefaultKeys bool
+}
+
+type scrubRule struct {
+	path   []string
+	key    *regexp.Regexp
+	action ScrubAction
+}
+
+// ScrubberOption configures a RuleScrubber.
+type ScrubberOption func(*RuleScrubber)
+
+// NewRuleScrubber returns a RuleScrubber applying the given rules, then masking the
+// values of keys matching DefaultScrubKeyPattern unless WithoutDefaultKeyRules is given.
+// The first matching rule applies.
+func NewRuleScrubber(opts ...ScrubberOption) *RuleScrubber {
+	s := &RuleScrubber{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if !s.noDefaultKeys {
+		s.rules = append(s.rules, scrubRule{key: DefaultScrubKeyPattern, action: ScrubMask})
+	}
+	return s
+}
+
+// WithoutDefaultKeyRules disables the masking of keys matching DefaultScrubKeyPattern, so
+// that only the configured rules apply.
+func WithoutDefaultKeyRules() ScrubberOption {
+	return func(s *RuleScrubber) {
+		s.noDefaultKeys = true
+	}
+}
+
+// WithScrubPaths applies action to the values at the given paths.
+func WithScrubPaths(action ScrubAction, paths ...string) ScrubberOption {
+	return func(s *RuleScrubber) {
+		for _, path := range paths {
+			s.rules = append(s.rules, scrubRule{path: splitScrubPath(path), action: action})
+		}
+	}
+}
+
+// WithScrubKeys applies action to the values of variables whose name matches one of
+// patterns, at any depth.
+func WithScrubKeys(action ScrubAction, patterns ...*regexp.Regexp) ScrubberOption {
+	return func(s *RuleScrubber) {
+		for _, pattern := range patterns {
+			s.rules = append(s.rules, scrubRule{key: pattern, action: action})
+		}
+	}
+}
+
+// WithAllowPaths switches the scrubber to allowlist mode: only values at or below the
+// given paths are logged as is and everything else is masked. Path and key rules still
+// apply below allowed paths.
+func WithAllowPaths(paths ...string) ScrubberOption {
+	return func(s *RuleScrubber) {
+		s.allowlist = true
+		for _, path := range paths {
+			s.allow = append(s.allow, splitScrubPath(path))
+		}
+	}
+}
+
+// WithScrubHashKey keys the hashes of ScrubHash with an HMAC, so that low-entropy values
+// such as emails cannot be recovered by hashing guesses.
+func WithScrubHashKey(key []byte) ScrubberOption {
+	return func(s *RuleScrubber) {
+		s.hashKey = key
+	}
+}
+
+// Scrub returns a scrubbed copy of vars.
+func (s *RuleScrubber) Scrub(vars map[string]any) map[string]any {
+	if vars == nil {
+		return nil
+	}
+	return s.scrubMap(vars, nil, !s.allowlist)
+}
+
+func (s *RuleScrubber) scrubMap(m map[string]any, path []string, allowed bool) map[string]any {
+	out := make(map[string]any, len(m))
+	for key, value := range m {
+		out[key] = s.scrub(value, appendPath(path, key), key, allowed)
+	}
+	return out
+}
+
+// scrub scrubs the value at path, key being its name or "" for slice elements.
+func (s *RuleScrubber) scrub(value any, path []string, key string, allowed bool) any {
+	if action, ok := s.match(path, key); ok {
+		return s.apply(value, action)
+	}
+	if !allowed {
+		allowed = s.allowed(path)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return s.scrubMap(v, path, allowed)
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = s.scrub(elem, appendPath(path, strconv.Itoa(i)), "", allowed)
+		}
+		return out
+	}
+
+	if !allowed {
+		return s.apply(value, ScrubMask)
+	}
+	return value
+}
+
+func (s *RuleScrubber) match(path []string, key string) (ScrubAction, bool) {
+	for _, rule := range s.rules {
+		if rule.key != nil {
+			if key != "" && rule.key.MatchString(key) {
+				return rule.action, true
+			}
+			continue
+		}
+		if matchScrubPath(rule.path, path) {
+			return rule.action, true
+		}
+	}
+	return 0, false
+}
+
+func (s *RuleScrubber) allowed(path []string) bool {
+	for _, allow := range s.allow {
+		if matchScrubPath(allow, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply applies action to every leaf of value. Nil values stay nil.
+func (s *RuleScrubber) apply(value any, action ScrubAction) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, elem := range v {
+			out[key] = s.apply(elem, action)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = s.apply(elem, action)
+		}
+		return out
+	}
+
+	if action == ScrubHash {
+		return s.hash(value)
+	}
+	return "REDACTED"
+}
+
+func (s *RuleScrubber) hash(value any) string {
+	data := []byte(fmt.Sprint(value))
+	var sum []byte
+	if len(s.hashKey) > 0 {
+		mac := hmac.New(sha256.New, s.hashKey)
+		mac.Write(data)
+		sum = mac.Sum(nil)
+	} else {
+		digest := sha256.Sum256(data)
+		sum = digest[:]
+	}
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+func splitScrubPath(path string) []string {
+	return strings.Split(strings.Trim(path, "."), ".")
+}
+
+func matchScrubPath(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, segment := range pattern {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func appendPath(path []string, segment string) []string {
+	return append(path[:len(path):len(path)], segment)
+}
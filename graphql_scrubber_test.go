@@ -0,0 +1,105 @@
+package log
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+)
+
+var _ = Describe("RuleScrubber", func() {
+	var vars map[string]any
+
+	BeforeEach(func() {
+		vars = map[string]any{
+			"id": "u1",
+			"input": map[string]any{
+				"name":     "Jane",
+				"password": "hunter2",
+				"users": []any{
+					map[string]any{"email": "a@example.com", "age": 30},
+					map[string]any{"email": "b@example.com", "age": nil},
+				},
+			},
+		}
+	})
+
+	It("should mask keys matching the default pattern and keep the shape", func() {
+		out := NewRuleScrubber().Scrub(vars)
+
+		g.Expect(out).To(g.Equal(map[string]any{
+			"id": "u1",
+			"input": map[string]any{
+				"name":     "Jane",
+				"password": "REDACTED",
+				"users": []any{
+					map[string]any{"email": "REDACTED", "age": 30},
+					map[string]any{"email": "REDACTED", "age": nil},
+				},
+			},
+		}))
+		g.Expect(vars["input"].(map[string]any)["password"]).To(g.Equal("hunter2"))
+	})
+
+	It("should apply path rules with wildcards", func() {
+		out := NewRuleScrubber(
+			WithScrubPaths(ScrubMask, "input.name"),
+			WithScrubPaths(ScrubHash, "input.users.*.email"),
+		).Scrub(vars)
+
+		input := out["input"].(map[string]any)
+		users := input["users"].([]any)
+		g.Expect(input["name"]).To(g.Equal("REDACTED"))
+		g.Expect(input["password"]).To(g.Equal("REDACTED"))
+		g.Expect(users[0].(map[string]any)["email"]).To(g.HavePrefix("sha256:"))
+		g.Expect(users[0].(map[string]any)["email"]).ToNot(g.Equal(users[1].(map[string]any)["email"]))
+		g.Expect(users[1].(map[string]any)["age"]).To(g.BeNil())
+	})
+
+	It("should only apply the configured rules without the default key rules", func() {
+		out := NewRuleScrubber(WithoutDefaultKeyRules(), WithScrubPaths(ScrubMask, "input.name")).Scrub(vars)
+
+		input := out["input"].(map[string]any)
+		g.Expect(input["name"]).To(g.Equal("REDACTED"))
+		g.Expect(input["password"]).To(g.Equal("hunter2"))
+		g.Expect(input["users"].([]any)[0].(map[string]any)["email"]).To(g.Equal("a@example.com"))
+	})
+
+	It("should mask every leaf below a matched object", func() {
+		out := NewRuleScrubber(WithScrubPaths(ScrubMask, "input.users")).Scrub(vars)
+
+		g.Expect(out["input"].(map[string]any)["users"]).To(g.Equal([]any{
+			map[string]any{"email": "REDACTED", "age": "REDACTED"},
+			map[string]any{"email": "REDACTED", "age": nil},
+		}))
+	})
+
+	It("should hash consistently and with the hash key", func() {
+		hash := NewRuleScrubber(WithScrubKeys(ScrubHash, regexp.MustCompile("^id$")))
+		keyed := NewRuleScrubber(WithScrubKeys(ScrubHash, regexp.MustCompile("^id$")), WithScrubHashKey([]byte("k")))
+
+		g.Expect(hash.Scrub(vars)["id"]).To(g.Equal(hash.Scrub(vars)["id"]))
+		g.Expect(keyed.Scrub(vars)["id"]).To(g.HavePrefix("sha256:"))
+		g.Expect(keyed.Scrub(vars)["id"]).ToNot(g.Equal(hash.Scrub(vars)["id"]))
+	})
+
+	It("should only pass allowed paths in allowlist mode", func() {
+		out := NewRuleScrubber(WithAllowPaths("id", "input.users")).Scrub(vars)
+
+		g.Expect(out).To(g.Equal(map[string]any{
+			"id": "u1",
+			"input": map[string]any{
+				"name":     "REDACTED",
+				"password": "REDACTED",
+				"users": []any{
+					map[string]any{"email": "REDACTED", "age": 30},
+					map[string]any{"email": "REDACTED", "age": nil},
+				},
+			},
+		}))
+	})
+
+	It("should return nil for nil variables", func() {
+		g.Expect(NewRuleScrubber().Scrub(nil)).To(g.BeNil())
+	})
+})
@@ -0,0 +1,179 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// DefaultSlowestResolvers is the number of slowest resolvers kept in the summary of a
+// ResolverTracer.
+const DefaultSlowestResolvers = 5
+
+var (
+	_ graphql.HandlerExtension     = (*ResolverTracer)(nil)
+	_ graphql.OperationInterceptor = (*ResolverTracer)(nil)
+	_ graphql.FieldInterceptor     = (*ResolverTracer)(nil)
+)
+
+// ResolverTracer is a gqlgen handler extension timing each resolver of an operation. Resolvers
+// taking at least the slow threshold are logged individually, and a summary with the
+// resolver count, the error count and the slowest resolvers is added to the entry of
+// NewSLogGraphQLResponseMiddleware. Register it with the server's Use method.
+type ResolverTracer struct {
+	l             *slog.Logger
+	slowThreshold time.Duration
+	slowest       int
+}
+
+// ResolverTracerOption configures a ResolverTracer.
+type ResolverTracerOption func(*ResolverTracer)
+
+// WithSlowResolverThreshold logs resolvers taking at least d at warn level.
+func WithSlowResolverThreshold(d time.Duration) ResolverTracerOption {
+	return func(t *ResolverTracer) {
+		t.slowThreshold = d
+	}
+}
+
+// WithSlowestResolvers sets how many of the slowest resolvers are kept in the summary.
+func WithSlowestResolvers(n int) ResolverTracerOption {
+	return func(t *ResolverTracer) {
+		if n >= 0 {
+			t.slowest = n
+		}
+	}
+}
+
+// NewSLogResolverTracer returns a ResolverTracer logging slow resolvers to `l`. If `l` is
+// nil, it uses the default logger.
+func NewSLogResolverTracer(l *slog.Logger, opts ...ResolverTracerOption) *ResolverTracer {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	t := &ResolverTracer{l: l, slowest: DefaultSlowestResolvers}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *ResolverTracer) ExtensionName() string {
+	return "ResolverTracer"
+}
+
+func (t *ResolverTracer) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation starts the trace of the operation. It runs before the response
+// middlewares, so the trace is visible to NewSLogGraphQLResponseMiddleware.
+func (t *ResolverTracer) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	return next(context.WithValue(ctx, resolverTraceKey{}, &resolverTrace{slowest: t.slowest}))
+}
+
+// InterceptField times the field if it is backed by a resolver.
+func (t *ResolverTracer) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	start := time.Now()
+	res, err := next(ctx)
+	timing := resolverTiming{
+		Field:    fc.Object + "." + fc.Field.Name,
+		Path:     fc.Path().String(),
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		timing.Error = err.Error()
+	}
+
+	if trace := getResolverTrace(ctx); trace != nil {
+		trace.add(timing)
+	}
+	if t.slowThreshold > 0 && timing.Duration >= t.slowThreshold {
+		attrs := []any{
+			slog.String("field", timing.Field),
+			slog.String("path", timing.Path),
+			slog.Duration("duration", timing.Duration),
+		}
+		if oc := graphql.GetOperationContext(ctx); oc != nil {
+			attrs = append([]any{slog.String("operationName", operationName(oc))}, attrs...)
+		}
+		if timing.Error != "" {
+			attrs = append(attrs, slog.String("error", timing.Error))
+		}
+		t.l.LogAttrs(ctx, slog.LevelWarn, "Slow GraphQL Resolver", slog.Group("graphql", attrs...))
+	}
+
+	return res, err
+}
+
+type resolverTraceKey struct{}
+
+// resolverTrace collects the resolver timings of an operation. Resolvers may run
+// concurrently.
+type resolverTrace struct {
+	mu      sync.Mutex
+	count   int
+	errors  int
+	slowest int
+	timings []resolverTiming
+}
+
+type resolverTiming struct {
+	Field    string        `json:"field"`
+	Path     string        `json:"path"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func getResolverTrace(ctx context.Context) *resolverTrace {
+	trace, _ := ctx.Value(resolverTraceKey{}).(*resolverTrace)
+	return trace
+}
+
+func (t *resolverTrace) add(timing resolverTiming) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.count++
+	if timing.Error != "" {
+		t.errors++
+	}
+	if t.slowest == 0 {
+		return
+	}
+
+	i := sort.Search(len(t.timings), func(i int) bool {
+		return t.timings[i].Duration < timing.Duration
+	})
+	if i >= t.slowest {
+		return
+	}
+	if len(t.timings) < t.slowest {
+		t.timings = append(t.timings, resolverTiming{})
+	}
+	copy(t.timings[i+1:], t.timings[i:])
+	t.timings[i] = timing
+}
+
+// attr returns the summary logged with the request entry.
+func (t *resolverTrace) attr() slog.Attr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slog.Group(
+		"resolvers",
+		slog.Int("count", t.count),
+		slog.Int("errors", t.errors),
+		slog.Any("slowest", append([]resolverTiming(nil), t.timings...)),
+	)
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/executor"
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var _ = Describe("ResolverTracer", func() {
+	type field struct {
+		name     string
+		delay    time.Duration
+		err      error
+		resolver bool
+	}
+
+	var (
+		buf bytes.Buffer
+		l   *slog.Logger
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		l = slog.New(slog.NewJSONHandler(&buf, nil))
+	})
+
+	// newExecutor returns an executor resolving the given fields one after the other, the
+	// way generated code does.
+	newExecutor := func(fields ...field) *executor.Executor {
+		schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+			type Query {
+				fast: String!
+				slow: String!
+				broken: String!
+				plain: String!
+			}
+		`})
+
+		return executor.New(&graphql.ExecutableSchemaMock{
+			ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+				return func(ctx context.Context) *graphql.Response {
+					for _, f := range fields {
+						f := f
+						fctx := graphql.WithFieldContext(ctx, &graphql.FieldContext{
+							Object:     "Query",
+							Field:      graphql.CollectedField{Field: &ast.Field{Name: f.name, Alias: f.name}},
+							IsResolver: f.resolver,
+						})
+						graphql.GetOperationContext(fctx).ResolverMiddleware(fctx, func(context.Context) (interface{}, error) {
+							time.Sleep(f.delay)
+							return f.name, f.err
+						})
+					}
+					return &graphql.Response{Data: []byte(`{}`)}
+				}
+			},
+			SchemaFunc: func() *ast.Schema {
+				return schema
+			},
+			ComplexityFunc: func(string, string, int, map[string]interface{}) (int, bool) {
+				return 0, false
+			},
+		})
+	}
+
+	run := func(exec *executor.Executor) {
+		ctx := graphql.StartOperationTrace(context.Background())
+		oc, errs := exec.CreateOperationContext(ctx, &graphql.RawParams{
+			Query:         "query Fields { fast slow broken plain }",
+			OperationName: "Fields",
+		})
+		g.Expect(errs).To(g.BeEmpty())
+
+		handler, ctx := exec.DispatchOperation(ctx, oc)
+		handler(ctx)
+	}
+
+	lines := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	It("should time resolvers and summarize them in the request entry", func() {
+		exec := newExecutor(
+			field{"fast", 0, nil, true},
+			field{"slow", 30 * time.Millisecond, nil, true},
+			field{"broken", 5 * time.Millisecond, errors.New("boom"), true},
+			field{"plain", 0, nil, false},
+		)
+		exec.Use(NewSLogResolverTracer(l, WithSlowResolverThreshold(20*time.Millisecond), WithSlowestResolvers(2)))
+		exec.AroundResponses(NewSLogGraphQLResponseMiddleware(l, nil))
+
+		run(exec)
+
+		logs := lines()
+		g.Expect(logs).To(g.HaveLen(2))
+
+		g.Expect(logs[0]["msg"]).To(g.Equal("Slow GraphQL Resolver"))
+		g.Expect(logs[0]["level"]).To(g.Equal("WARN"))
+		g.Expect(logs[0]["graphql"]).To(g.HaveKeyWithValue("operationName", "Fields"))
+		g.Expect(logs[0]["graphql"]).To(g.HaveKeyWithValue("field", "Query.slow"))
+		g.Expect(logs[0]["graphql"]).To(g.HaveKeyWithValue("path", "slow"))
+
+		g.Expect(logs[1]["msg"]).To(g.Equal("GraphQL Request Served"))
+		resolvers := logs[1]["graphql"].(map[string]interface{})["resolvers"].(map[string]interface{})
+		g.Expect(resolvers["count"]).To(g.BeNumerically("==", 3))
+		g.Expect(resolvers["errors"]).To(g.BeNumerically("==", 1))
+
+		slowest := resolvers["slowest"].([]interface{})
+		g.Expect(slowest).To(g.HaveLen(2))
+		g.Expect(slowest[0]).To(g.HaveKeyWithValue("field", "Query.slow"))
+		g.Expect(slowest[1]).To(g.HaveKeyWithValue("field", "Query.broken"))
+		g.Expect(slowest[1]).To(g.HaveKeyWithValue("error", "boom"))
+	})
+
+	It("should leave the request entry unchanged without the tracer", func() {
+		exec := newExecutor(field{"fast", 0, nil, true})
+		exec.AroundResponses(NewSLogGraphQLResponseMiddleware(l, nil))
+
+		run(exec)
+
+		logs := lines()
+		g.Expect(logs).To(g.HaveLen(1))
+		g.Expect(logs[0]["graphql"]).ToNot(g.HaveKey("resolvers"))
+	})
+})
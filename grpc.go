@@ -0,0 +1,452 @@
+package log
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultGRPCRequestIDKey is the metadata key the gRPC interceptors read the request ID
+// from.
+const DefaultGRPCRequestIDKey = "x-request-id"
+
+// GRPCOption configures the call logging of the gRPC interceptors.
+type GRPCOption func(*grpcOptions)
+
+type grpcOptions struct {
+	codeLevel    func(codes.Code) Level
+	requestIDKey string
+	skipMethods  map[string]struct{}
+	message      string
+}
+
+func newGRPCOptions(opts []GRPCOption) *grpcOptions {
+	o := &grpcOptions{codeLevel: DefaultGRPCCodeLevel, requestIDKey: DefaultGRPCRequestIDKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithGRPCCodeLevels sets the function picking the level of the call log entry from the
+// status code. It defaults to DefaultGRPCCodeLevel.
+func WithGRPCCodeLevels(level func(codes.Code) Level) GRPCOption {
+	return func(o *grpcOptions) {
+		if level != nil {
+			o.codeLevel = level
+		}
+	}
+}
+
+// WithGRPCRequestIDKey sets the metadata key holding the request ID.
+func WithGRPCRequestIDKey(key string) GRPCOption {
+	return func(o *grpcOptions) {
+		o.requestIDKey = key
+	}
+}
+
+// WithGRPCSkipMethods skips logging calls to the given full methods, such as
+// "/grpc.health.v1.Health/Check".
+func WithGRPCSkipMethods(methods ...string) GRPCOption {
+	return func(o *grpcOptions) {
+		if o.skipMethods == nil {
+			o.skipMethods = make(map[string]struct{}, len(methods))
+		}
+		for _, method := range methods {
+			o.skipMethods[method] = struct{}{}
+		}
+	}
+}
+
+// WithGRPCMessage replaces the message of the call log entry.
+func WithGRPCMessage(message string) GRPCOption {
+	return func(o *grpcOptions) {
+		o.message = message
+	}
+}
+
+// DefaultGRPCCodeLevel logs OK calls at info level, codes caused by the caller at warn
+// level and codes caused by the server at error level, in line with WithStatusLevels.
+func DefaultGRPCCodeLevel(code codes.Code) Level {
+	switch code {
+	case codes.OK:
+		return logrus.InfoLevel
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal,
+		codes.Unavailable, codes.DataLoss:
+		return logrus.ErrorLevel
+	default:
+		return logrus.WarnLevel
+	}
+}
+
+type grpcLogFunc func(ctx context.Context, level Level, message string, fields []httpField)
+
+// UnaryServerInterceptor logs each unary call with its full method, status code,
+// duration, peer address, message sizes and request ID, at a level derived from the
+// status code. The context logging fields are initialized for the handler.
+func UnaryServerInterceptor(opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	return newGRPCOptions(opts).unaryServer(logGRPCLogrus)
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor. Message
+// sizes are summed over the stream.
+func StreamServerInterceptor(opts ...GRPCOption) grpc.StreamServerInterceptor {
+	return newGRPCOptions(opts).streamServer(logGRPCLogrus)
+}
+
+// UnaryClientInterceptor logs each unary call made by a client. The request ID of the
+// calling context is sent in the metadata unless it already holds one.
+func UnaryClientInterceptor(opts ...GRPCOption) grpc.UnaryClientInterceptor {
+	return newGRPCOptions(opts).unaryClient(logGRPCLogrus)
+}
+
+// StreamClientInterceptor is the streaming counterpart of UnaryClientInterceptor. The call
+// is logged once the stream ends, which the caller sees as RecvMsg returning an error,
+// io.EOF included.
+func StreamClientInterceptor(opts ...GRPCOption) grpc.StreamClientInterceptor {
+	return newGRPCOptions(opts).streamClient(logGRPCLogrus)
+}
+
+// NewSLogUnaryServerInterceptor is the slog flavor of UnaryServerInterceptor. If `l` is
+// nil, it uses the default logger.
+func NewSLogUnaryServerInterceptor(l *slog.Logger, opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	return newGRPCOptions(opts).unaryServer(logGRPCSLog(l))
+}
+
+// NewSLogStreamServerInterceptor is the slog flavor of StreamServerInterceptor. If `l` is
+// nil, it uses the default logger.
+func NewSLogStreamServerInterceptor(l *slog.Logger, opts ...GRPCOption) grpc.StreamServerInterceptor {
+	return newGRPCOptions(opts).streamServer(logGRPCSLog(l))
+}
+
+// NewSLogUnaryClientInterceptor is the slog flavor of UnaryClientInterceptor. If `l` is
+// nil, it uses the default logger.
+func NewSLogUnaryClientInterceptor(l *slog.Logger, opts ...GRPCOption) grpc.UnaryClientInterceptor {
+	return newGRPCOptions(opts).unaryClient(logGRPCSLog(l))
+}
+
+// NewSLogStreamClientInterceptor is the slog flavor of StreamClientInterceptor. If `l` is
+// nil, it uses the default logger.
+func NewSLogStreamClientInterceptor(l *slog.Logger, opts ...GRPCOption) grpc.StreamClientInterceptor {
+	return newGRPCOptions(opts).streamClient(logGRPCSLog(l))
+}
+
+func logGRPCLogrus(_ context.Context, level Level, message string, fields []httpField) {
+	Default().WithFields(httpLogrusFields(fields)).Log(level, message)
+}
+
+func logGRPCSLog(l *slog.Logger) grpcLogFunc {
+	if l == nil {
+		l = slog.Default()
+	}
+	return func(ctx context.Context, level Level, message string, fields []httpField) {
+		l.LogAttrs(ctx, slogLevel(level), message, httpSLogAttrs(fields)...)
+	}
+}
+
+func (o *grpcOptions) skip(method string) bool {
+	_, ok := o.skipMethods[method]
+	return ok
+}
+
+func (o *grpcOptions) unaryServer(logCall grpcLogFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if o.skip(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		call := newGRPCCall(info.FullMethod, "unary", false)
+		ctx = o.prepareServerContext(ctx, call)
+		call.received(req)
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			call.sent(resp)
+		}
+		o.logCall(ctx, call, err, logCall)
+		return resp, err
+	}
+}
+
+func (o *grpcOptions) streamServer(logCall grpcLogFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if o.skip(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		call := newGRPCCall(info.FullMethod, grpcStreamType(info.IsClientStream, info.IsServerStream), false)
+		ctx := o.prepareServerContext(ss.Context(), call)
+
+		err := handler(srv, &loggingServerStream{ServerStream: ss, ctx: ctx, call: call})
+		o.logCall(ctx, call, err, logCall)
+		return err
+	}
+}
+
+func (o *grpcOptions) unaryClient(logCall grpcLogFunc) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if o.skip(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		call := newGRPCCall(method, "unary", true)
+		ctx = o.prepareClientContext(ctx, call)
+		call.sent(req)
+
+		p := &peer.Peer{}
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(p))...)
+		if err == nil {
+			call.received(reply)
+		}
+		call.setPeer(p)
+		o.logCall(ctx, call, err, logCall)
+		return err
+	}
+}
+
+func (o *grpcOptions) streamClient(logCall grpcLogFunc) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if o.skip(method) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		call := newGRPCCall(method, grpcStreamType(desc.ClientStreams, desc.ServerStreams), true)
+		ctx = o.prepareClientContext(ctx, call)
+
+		p := &peer.Peer{}
+		finish := func(err error) {
+			call.setPeer(p)
+			o.logCall(ctx, call, err, logCall)
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, append(opts, grpc.Peer(p))...)
+		if err != nil {
+			finish(err)
+			return nil, err
+		}
+		return &loggingClientStream{ClientStream: cs, call: call, serverStreams: desc.ServerStreams, finish: finish}, nil
+	}
+}
+
+// prepareServerContext makes the request ID from the incoming metadata available to
+// RequestLogger and the context logging fields, and initializes the context logging fields
+// with the method and peer address.
+func (o *grpcOptions) prepareServerContext(ctx context.Context, call *grpcCall) context.Context {
+	if p, ok := peer.FromContext(ctx); ok {
+		call.setPeer(p)
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok && o.requestIDKey != "" {
+		if ids := md.Get(o.requestIDKey); len(ids) > 0 && ids[0] != "" {
+			call.requestID = ids[0]
+			ctx = context.WithValue(ctx, middleware.RequestIDKey, call.requestID)
+		}
+	}
+
+	fields := []Field{MakeField("method", call.method)}
+	if call.peer != "" {
+		fields = append(fields, MakeField("peer", call.peer))
+	}
+	return ContextWithFields(ctx, fields...)
+}
+
+// prepareClientContext picks the request ID of the call, sending the one of ctx along
+// when the outgoing metadata does not hold one yet.
+func (o *grpcOptions) prepareClientContext(ctx context.Context, call *grpcCall) context.Context {
+	if o.requestIDKey == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if ids := md.Get(o.requestIDKey); len(ids) > 0 && ids[0] != "" {
+			call.requestID = ids[0]
+			return ctx
+		}
+	}
+	if call.requestID = middleware.GetReqID(ctx); call.requestID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, o.requestIDKey, call.requestID)
+	}
+	return ctx
+}
+
+func (o *grpcOptions) logCall(ctx context.Context, call *grpcCall, err error, logCall grpcLogFunc) {
+	code := status.Code(err)
+	message := "gRPC Request Served"
+	if call.client {
+		message = "gRPC Call Finished"
+	}
+	if o.message != "" {
+		message = o.message
+	}
+	logCall(ctx, o.codeLevel(code), message, withContextFields(ctx, call.fields(code, err)))
+}
+
+// grpcCall collects the details of a call as it progresses. Sent and received are from the
+// point of view of the interceptor's side of the call.
+type grpcCall struct {
+	method     string
+	streamType string
+	client     bool
+	start      time.Time
+	peer       string
+	requestID  string
+
+	sentMessages     atomic.Int64
+	receivedMessages atomic.Int64
+	sentBytes        atomic.Int64
+	receivedBytes    atomic.Int64
+}
+
+func newGRPCCall(method, streamType string, client bool) *grpcCall {
+	return &grpcCall{method: method, streamType: streamType, client: client, start: time.Now()}
+}
+
+func grpcStreamType(clientStreams, serverStreams bool) string {
+	switch {
+	case clientStreams && serverStreams:
+		return "bidi_stream"
+	case clientStreams:
+		return "client_stream"
+	case serverStreams:
+		return "server_stream"
+	default:
+		return "unary"
+	}
+}
+
+func (c *grpcCall) setPeer(p *peer.Peer) {
+	if p != nil && p.Addr != nil {
+		c.peer = p.Addr.String()
+	}
+}
+
+func (c *grpcCall) sent(m interface{}) {
+	c.sentMessages.Add(1)
+	c.sentBytes.Add(int64(messageSize(m)))
+}
+
+func (c *grpcCall) received(m interface{}) {
+	c.receivedMessages.Add(1)
+	c.receivedBytes.Add(int64(messageSize(m)))
+}
+
+// fields collects the fields logged for a finished call, omitting empty ones.
+func (c *grpcCall) fields(code codes.Code, err error) []httpField {
+	requestMessages, responseMessages := &c.receivedMessages, &c.sentMessages
+	requestBytes, responseBytes := &c.receivedBytes, &c.sentBytes
+	if c.client {
+		requestMessages, responseMessages = responseMessages, requestMessages
+		requestBytes, responseBytes = responseBytes, requestBytes
+	}
+
+	fields := []httpField{
+		{"method", c.method},
+		{"type", c.streamType},
+		{"code", code.String()},
+		{"duration", time.Since(c.start)},
+		{"peer", c.peer},
+		{"requestID", c.requestID},
+		{"requestSize", requestBytes.Load()},
+		{"responseSize", responseBytes.Load()},
+	}
+	if c.streamType != "unary" {
+		fields = append(fields,
+			httpField{"requestMessages", requestMessages.Load()},
+			httpField{"responseMessages", responseMessages.Load()},
+		)
+	}
+	if err != nil {
+		fields = append(fields, httpField{"error", status.Convert(err).Message()})
+	}
+
+	out := fields[:0]
+	for _, f := range fields {
+		if s, ok := f.value.(string); ok && s == "" {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// messageSize returns the encoded size of protobuf messages, and 0 for anything else.
+func messageSize(m interface{}) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+	return 0
+}
+
+type loggingServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	call *grpcCall
+}
+
+func (s *loggingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggingServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.call.sent(m)
+	}
+	return err
+}
+
+func (s *loggingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.call.received(m)
+	}
+	return err
+}
+
+type loggingClientStream struct {
+	grpc.ClientStream
+	call          *grpcCall
+	serverStreams bool
+	finish        func(error)
+	once          sync.Once
+}
+
+func (s *loggingClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.call.sent(m)
+	}
+	return err
+}
+
+func (s *loggingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.call.received(m)
+		if !s.serverStreams {
+			s.done(nil)
+		}
+	case err == io.EOF:
+		s.done(nil)
+	default:
+		s.done(err)
+	}
+	return err
+}
+
+func (s *loggingClientStream) done(err error) {
+	s.once.Do(func() {
+		s.finish(err)
+	})
+}
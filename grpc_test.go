@@ -0,0 +1,259 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+
+	"github.com/go-chi/chi/middleware"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var _ = Describe("gRPC interceptors", func() {
+	var (
+		buf      bytes.Buffer
+		previous *logrus.Logger
+		server   *grpc.Server
+		conn     *grpc.ClientConn
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		previous = Default()
+		SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+	})
+
+	AfterEach(func() {
+		SetDefault(previous)
+	})
+
+	start := func(serverOpts []grpc.ServerOption, dialOpts ...grpc.DialOption) healthpb.HealthClient {
+		lis := bufconn.Listen(1 << 20)
+		server = grpc.NewServer(serverOpts...)
+		hs := health.NewServer()
+		hs.SetServingStatus("users", healthpb.HealthCheckResponse_SERVING)
+		healthpb.RegisterHealthServer(server, hs)
+		go server.Serve(lis)
+
+		var err error
+		conn, err = grpc.DialContext(
+			context.Background(),
+			"bufnet",
+			append(dialOpts,
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return lis.DialContext(ctx)
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			)...,
+		)
+		g.Expect(err).ToNot(g.HaveOccurred())
+		return healthpb.NewHealthClient(conn)
+	}
+
+	// stop waits for the handlers to return so that every call has been logged.
+	stop := func() {
+		conn.Close()
+		server.GracefulStop()
+	}
+
+	lines := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	pushUser := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		PushContextFields(ctx, MakeField("user", "u1"))
+		return handler(ctx, req)
+	}
+
+	Describe("UnaryServerInterceptor", func() {
+		It("should log the call with the request ID and context fields", func() {
+			client := start([]grpc.ServerOption{grpc.ChainUnaryInterceptor(UnaryServerInterceptor(), pushUser)})
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), DefaultGRPCRequestIDKey, "req-1")
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "users"})
+			g.Expect(err).ToNot(g.HaveOccurred())
+			stop()
+
+			logs := lines()
+			g.Expect(logs).To(g.HaveLen(1))
+			g.Expect(logs[0]["msg"]).To(g.Equal("gRPC Request Served"))
+			g.Expect(logs[0]["level"]).To(g.Equal("info"))
+			g.Expect(logs[0]["method"]).To(g.Equal("/grpc.health.v1.Health/Check"))
+			g.Expect(logs[0]["type"]).To(g.Equal("unary"))
+			g.Expect(logs[0]["code"]).To(g.Equal("OK"))
+			g.Expect(logs[0]["peer"]).To(g.Equal("bufconn"))
+			g.Expect(logs[0]["requestID"]).To(g.Equal("req-1"))
+			g.Expect(logs[0]["requestSize"]).To(g.BeNumerically("==", 7))
+			g.Expect(logs[0]["responseSize"]).To(g.BeNumerically("==", 2))
+			g.Expect(logs[0]["duration"]).To(g.BeNumerically(">", 0))
+			g.Expect(logs[0]["user"]).To(g.Equal("u1"))
+			g.Expect(logs[0]).ToNot(g.HaveKey("error"))
+		})
+
+		It("should derive the level from the status code", func() {
+			client := start([]grpc.ServerOption{grpc.UnaryInterceptor(UnaryServerInterceptor())})
+
+			_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "orders"})
+			g.Expect(status.Code(err)).To(g.Equal(codes.NotFound))
+			stop()
+
+			logs := lines()
+			g.Expect(logs).To(g.HaveLen(1))
+			g.Expect(logs[0]["level"]).To(g.Equal("warning"))
+			g.Expect(logs[0]["code"]).To(g.Equal("NotFound"))
+			g.Expect(logs[0]["error"]).To(g.Equal("unknown service"))
+			g.Expect(logs[0]).ToNot(g.HaveKey("requestID"))
+		})
+
+		It("should apply the options", func() {
+			client := start([]grpc.ServerOption{grpc.UnaryInterceptor(UnaryServerInterceptor(
+				WithGRPCCodeLevels(func(codes.Code) Level { return logrus.ErrorLevel }),
+				WithGRPCRequestIDKey("x-correlation-id"),
+				WithGRPCMessage("served"),
+			))})
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-correlation-id", "corr-1")
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "users"})
+			g.Expect(err).ToNot(g.HaveOccurred())
+			stop()
+
+			logs := lines()
+			g.Expect(logs).To(g.HaveLen(1))
+			g.Expect(logs[0]["msg"]).To(g.Equal("served"))
+			g.Expect(logs[0]["level"]).To(g.Equal("error"))
+			g.Expect(logs[0]["requestID"]).To(g.Equal("corr-1"))
+		})
+
+		It("should skip the given methods", func() {
+			client := start([]grpc.ServerOption{grpc.UnaryInterceptor(UnaryServerInterceptor(
+				WithGRPCSkipMethods("/grpc.health.v1.Health/Check"),
+			))})
+
+			_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "users"})
+			g.Expect(err).ToNot(g.HaveOccurred())
+			stop()
+
+			g.Expect(buf.Len()).To(g.BeZero())
+		})
+	})
+
+	Describe("StreamServerInterceptor", func() {
+		It("should log the stream once it ends", func() {
+			client := start([]grpc.ServerOption{grpc.StreamInterceptor(StreamServerInterceptor())})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "users"})
+			g.Expect(err).ToNot(g.HaveOccurred())
+			_, err = stream.Recv()
+			g.Expect(err).ToNot(g.HaveOccurred())
+			cancel()
+			stop()
+
+			logs := lines()
+			g.Expect(logs).To(g.HaveLen(1))
+			g.Expect(logs[0]["method"]).To(g.Equal("/grpc.health.v1.Health/Watch"))
+			g.Expect(logs[0]["type"]).To(g.Equal("server_stream"))
+			g.Expect(logs[0]["code"]).To(g.Equal("Canceled"))
+			g.Expect(logs[0]["requestMessages"]).To(g.BeNumerically("==", 1))
+			g.Expect(logs[0]["responseMessages"]).To(g.BeNumerically("==", 1))
+			g.Expect(logs[0]["requestSize"]).To(g.BeNumerically("==", 7))
+			g.Expect(logs[0]["responseSize"]).To(g.BeNumerically("==", 2))
+		})
+	})
+
+	Describe("UnaryClientInterceptor", func() {
+		It("should log the call and send the request ID along", func() {
+			client := start(
+				[]grpc.ServerOption{grpc.UnaryInterceptor(UnaryServerInterceptor())},
+				grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+			)
+
+			ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-2")
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "users"})
+			g.Expect(err).ToNot(g.HaveOccurred())
+			stop()
+
+			logs := lines()
+			g.Expect(logs).To(g.HaveLen(2))
+			g.Expect(logs[0]["msg"]).To(g.Equal("gRPC Request Served"))
+			g.Expect(logs[0]["requestID"]).To(g.Equal("req-2"))
+
+			g.Expect(logs[1]["msg"]).To(g.Equal("gRPC Call Finished"))
+			g.Expect(logs[1]["code"]).To(g.Equal("OK"))
+			g.Expect(logs[1]["peer"]).To(g.Equal("bufconn"))
+			g.Expect(logs[1]["requestID"]).To(g.Equal("req-2"))
+			g.Expect(logs[1]["requestSize"]).To(g.BeNumerically("==", 7))
+			g.Expect(logs[1]["responseSize"]).To(g.BeNumerically("==", 2))
+		})
+	})
+
+	Describe("StreamClientInterceptor", func() {
+		It("should log the stream once it ends", func() {
+			client := start(nil, grpc.WithStreamInterceptor(StreamClientInterceptor()))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "users"})
+			g.Expect(err).ToNot(g.HaveOccurred())
+			_, err = stream.Recv()
+			g.Expect(err).ToNot(g.HaveOccurred())
+			cancel()
+			_, err = stream.Recv()
+			g.Expect(status.Code(err)).To(g.Equal(codes.Canceled))
+			stop()
+
+			logs := lines()
+			g.Expect(logs).To(g.HaveLen(1))
+			g.Expect(logs[0]["msg"]).To(g.Equal("gRPC Call Finished"))
+			g.Expect(logs[0]["type"]).To(g.Equal("server_stream"))
+			g.Expect(logs[0]["code"]).To(g.Equal("Canceled"))
+			g.Expect(logs[0]["requestMessages"]).To(g.BeNumerically("==", 1))
+			g.Expect(logs[0]["responseMessages"]).To(g.BeNumerically("==", 1))
+		})
+	})
+
+	Describe("NewSLogUnaryServerInterceptor", func() {
+		It("should log the call", func() {
+			var out bytes.Buffer
+			l := slog.New(slog.NewJSONHandler(&out, nil))
+			client := start([]grpc.ServerOption{grpc.ChainUnaryInterceptor(NewSLogUnaryServerInterceptor(l), pushUser)})
+
+			_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "orders"})
+			g.Expect(status.Code(err)).To(g.Equal(codes.NotFound))
+			stop()
+
+			b := ByteLogs{Log: &out}
+			b.Parse(nil)
+			g.Expect(b.Parsed).To(g.HaveLen(1))
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("gRPC Request Served"))
+			g.Expect(b.Parsed[0]["level"]).To(g.Equal("WARN"))
+			g.Expect(b.Parsed[0]["code"]).To(g.Equal("NotFound"))
+			g.Expect(b.Parsed[0]["user"]).To(g.Equal("u1"))
+			g.Expect(buf.Len()).To(g.BeZero())
+		})
+	})
+
+	table.DescribeTable("DefaultGRPCCodeLevel",
+		func(code codes.Code, level Level) {
+			g.Expect(DefaultGRPCCodeLevel(code)).To(g.Equal(level))
+		},
+		table.Entry("OK", codes.OK, logrus.InfoLevel),
+		table.Entry("InvalidArgument", codes.InvalidArgument, logrus.WarnLevel),
+		table.Entry("PermissionDenied", codes.PermissionDenied, logrus.WarnLevel),
+		table.Entry("Internal", codes.Internal, logrus.ErrorLevel),
+		table.Entry("Unavailable", codes.Unavailable, logrus.ErrorLevel),
+	)
+})
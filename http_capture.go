@@ -0,0 +1,218 @@
+package log
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// DefaultHeaderDenylist holds the headers whose values are never logged.
+var DefaultHeaderDenylist = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// DefaultBodyContentTypes holds the media types whose bodies are captured unless
+// WithBodyContentTypes is used.
+var DefaultBodyContentTypes = []string{
+	"application/json",
+	"application/graphql",
+	"application/x-www-form-urlencoded",
+	"application/xml",
+	"text/*",
+}
+
+type captureOptions struct {
+	requestHeaders  []string
+	responseHeaders []string
+	denylist        map[string]struct{}
+	requestBody     int
+	responseBody    int
+	contentTypes    []string
+	redactor        func(contentType string, body []byte) []byte
+}
+
+// WithRequestHeaders logs the named request headers under request.headers. Headers in the
+// denylist are logged as "REDACTED".
+func WithRequestHeaders(names ...string) HTTPOption {
+	return func(o *httpOptions) {
+		o.capture.requestHeaders = append(o.capture.requestHeaders, names...)
+	}
+}
+
+// WithResponseHeaders logs the named response headers under response.headers. Headers in
+// the denylist are logged as "REDACTED".
+func WithResponseHeaders(names ...string) HTTPOption {
+	return func(o *httpOptions) {
+		o.capture.responseHeaders = append(o.capture.responseHeaders, names...)
+	}
+}
+
+// WithHeaderDenylist adds headers to DefaultHeaderDenylist.
+func WithHeaderDenylist(names ...string) HTTPOption {
+	return func(o *httpOptions) {
+		for _, name := range names {
+			o.capture.denylist[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+}
+
+// WithRequestBody logs up to maxBytes of the request body read by the handler under
+// request.body.
+func WithRequestBody(maxBytes int) HTTPOption {
+	return func(o *httpOptions) {
+		o.capture.requestBody = maxBytes
+	}
+}
+
+// WithResponseBody logs up to maxBytes of the response body under response.body.
+func WithResponseBody(maxBytes int) HTTPOption {
+	return func(o *httpOptions) {
+		o.capture.responseBody = maxBytes
+	}
+}
+
+// WithBodyContentTypes replaces DefaultBodyContentTypes. Entries are media types such as
+// "application/json" or wildcards such as "text/*".
+func WithBodyContentTypes(types ...string) HTTPOption {
+	return func(o *httpOptions) {
+		o.capture.contentTypes = types
+	}
+}
+
+// WithBodyRedactor sets a function applied to captured bodies before they are logged.
+func WithBodyRedactor(redact func(contentType string, body []byte) []byte) HTTPOption {
+	return func(o *httpOptions) {
+		o.capture.redactor = redact
+	}
+}
+
+func newCaptureOptions() captureOptions {
+	denylist := make(map[string]struct{}, len(DefaultHeaderDenylist))
+	for _, name := range DefaultHeaderDenylist {
+		denylist[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+	return captureOptions{denylist: denylist, contentTypes: DefaultBodyContentTypes}
+}
+
+// limitedBuffer keeps the first max bytes written to it.
+type limitedBuffer struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - len(b.buf); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf = append(b.buf, p[:room]...)
+		}
+	} else {
+		b.buf = append(b.buf, p...)
+	}
+	return len(p), nil
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// httpCapture holds what was captured for one request.
+type httpCapture struct {
+	opts         *captureOptions
+	request      *http.Request
+	response     middleware.WrapResponseWriter
+	requestBody  *limitedBuffer
+	responseBody *limitedBuffer
+}
+
+// startCapture prepares capturing for a request, or returns nil when capturing is off.
+func (o *captureOptions) startCapture(r *http.Request, ww middleware.WrapResponseWriter) *httpCapture {
+	if len(o.requestHeaders) == 0 && len(o.responseHeaders) == 0 && o.requestBody <= 0 && o.responseBody <= 0 {
+		return nil
+	}
+
+	c := &httpCapture{opts: o, request: r, response: ww}
+	if o.requestBody > 0 && r.Body != nil && r.Body != http.NoBody && o.capturesContentType(r.Header.Get("Content-Type")) {
+		c.requestBody = &limitedBuffer{max: o.requestBody}
+		r.Body = teeReadCloser{Reader: io.TeeReader(r.Body, c.requestBody), Closer: r.Body}
+	}
+	if o.responseBody > 0 {
+		c.responseBody = &limitedBuffer{max: o.responseBody}
+		ww.Tee(c.responseBody)
+	}
+	return c
+}
+
+func (o *captureOptions) capturesContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range o.contentTypes {
+		if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns the captured request and response data as nested fields.
+func (c *httpCapture) fields() []httpField {
+	if c == nil {
+		return nil
+	}
+
+	var fields []httpField
+	req := c.section(c.opts.requestHeaders, c.request.Header, c.requestBody, c.request.Header.Get("Content-Type"))
+	if len(req) > 0 {
+		fields = append(fields, httpField{"request", req})
+	}
+
+	var body *limitedBuffer
+	if c.responseBody != nil && c.opts.capturesContentType(c.response.Header().Get("Content-Type")) {
+		body = c.responseBody
+	}
+	res := c.section(c.opts.responseHeaders, c.response.Header(), body, c.response.Header().Get("Content-Type"))
+	if len(res) > 0 {
+		fields = append(fields, httpField{"response", res})
+	}
+	return fields
+}
+
+func (c *httpCapture) section(names []string, header http.Header, body *limitedBuffer, contentType string) map[string]interface{} {
+	section := make(map[string]interface{})
+
+	if len(names) > 0 {
+		headers := make(map[string]interface{})
+		for _, name := range names {
+			name = http.CanonicalHeaderKey(name)
+			values := header.Values(name)
+			if len(values) == 0 {
+				continue
+			}
+			if _, denied := c.opts.denylist[name]; denied {
+				headers[name] = "REDACTED"
+			} else {
+				headers[name] = strings.Join(values, ", ")
+			}
+		}
+		if len(headers) > 0 {
+			section["headers"] = headers
+		}
+	}
+
+	if body != nil && len(body.buf) > 0 {
+		content := body.buf
+		if c.opts.redactor != nil {
+			content = c.opts.redactor(contentType, content)
+		}
+		section["body"] = string(content)
+		if body.truncated {
+			section["bodyTruncated"] = true
+		}
+	}
+	return section
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTP capture", func() {
+	var buf bytes.Buffer
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+	})
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Trace", "t1")
+		w.Write(body)
+	})
+
+	request := func(body, contentType string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		r.Header.Set("Authorization", "Bearer secret")
+		r.Header.Set("X-Client", "c1")
+		return r
+	}
+
+	parse := func() map[string]interface{} {
+		var out map[string]interface{}
+		g.Expect(json.Unmarshal(buf.Bytes(), &out)).To(g.Succeed())
+		return out
+	}
+
+	It("should not capture anything by default", func() {
+		rec := httptest.NewRecorder()
+		NewSLogChiMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)))(echo).ServeHTTP(rec, request(`{"a":1}`, "application/json"))
+
+		out := parse()
+		g.Expect(out).ToNot(g.HaveKey("request"))
+		g.Expect(out).ToNot(g.HaveKey("response"))
+	})
+
+	It("should capture selected headers and bodies", func() {
+		mw := NewSLogChiMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)),
+			WithRequestHeaders("x-client", "authorization", "x-missing"),
+			WithResponseHeaders("X-Trace", "Set-Cookie"),
+			WithRequestBody(1024),
+			WithResponseBody(5),
+		)
+		rec := httptest.NewRecorder()
+		mw(echo).ServeHTTP(rec, request(`{"a":1}`, "application/json; charset=utf-8"))
+
+		g.Expect(rec.Body.String()).To(g.Equal(`{"a":1}`))
+
+		out := parse()
+		g.Expect(out["request"]).To(g.Equal(map[string]interface{}{
+			"headers": map[string]interface{}{"X-Client": "c1", "Authorization": "REDACTED"},
+			"body":    `{"a":1}`,
+		}))
+		g.Expect(out["response"]).To(g.Equal(map[string]interface{}{
+			"headers":       map[string]interface{}{"X-Trace": "t1", "Set-Cookie": "REDACTED"},
+			"body":          `{"a":`,
+			"bodyTruncated": true,
+		}))
+	})
+
+	It("should skip bodies with other content types", func() {
+		mw := NewSLogChiMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)),
+			WithRequestBody(1024),
+			WithBodyContentTypes("text/*"),
+		)
+		mw(echo).ServeHTTP(httptest.NewRecorder(), request(`{"a":1}`, "application/json"))
+
+		g.Expect(parse()).ToNot(g.HaveKey("request"))
+	})
+
+	It("should redact bodies and extend the denylist with ServerLogger", func() {
+		previous := Default()
+		defer SetDefault(previous)
+		SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+
+		mw := ServerLogger(
+			WithRequestHeaders("X-Client"),
+			WithHeaderDenylist("x-client"),
+			WithRequestBody(1024),
+			WithBodyRedactor(func(contentType string, body []byte) []byte {
+				return bytes.ReplaceAll(body, []byte("hunter2"), []byte("***"))
+			}),
+		)
+		mw(echo).ServeHTTP(httptest.NewRecorder(), request(`password=hunter2`, "application/x-www-form-urlencoded"))
+
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		g.Expect(b.Parsed[0]["request"]).To(g.Equal(map[string]interface{}{
+			"headers": map[string]interface{}{"X-Client": "REDACTED"},
+			"body":    "password=***",
+		}))
+	})
+})
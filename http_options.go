@@ -0,0 +1,469 @@
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPOption configures the HTTP request logging of ServerLogger and NewSLogChiMiddleware.
+type HTTPOption func(*httpOptions)
+
+type httpOptions struct {
+	trustedProxies []netip.Prefix
+	queryScrubber  func(url.Values) url.Values
+	statusLevels   bool
+	slowThreshold  time.Duration
+	message        string
+	fieldNames     map[string]string
+	skipPrefixes   []string
+	skipPatterns   []*regexp.Regexp
+	skipMethods    map[string]struct{}
+	skipFuncs      []func(*http.Request) bool
+	sampleRate     uint64
+	sampleCounts   sync.Map
+	counters       *HTTPLogCounters
+	capture        captureOptions
+}
+
+// HTTPLogCounters counts request log entries suppressed by skip and sampling rules. It is
+// safe for concurrent use.
+type HTTPLogCounters struct {
+	skipped atomic.Uint64
+	sampled atomic.Uint64
+}
+
+// Skipped returns the number of requests not logged because of a skip rule.
+func (c *HTTPLogCounters) Skipped() uint64 {
+	return c.skipped.Load()
+}
+
+// Sampled returns the number of requests not logged because of sampling.
+func (c *HTTPLogCounters) Sampled() uint64 {
+	return c.sampled.Load()
+}
+
+func newHTTPOptions(opts []HTTPOption) *httpOptions {
+	o := &httpOptions{counters: &HTTPLogCounters{}, capture: newCaptureOptions()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTrustedProxies sets the proxies whose X-Forwarded-For, X-Real-IP and Forwarded
+// headers are trusted when resolving the client IP. Without trusted proxies the client IP
+// is the remote address of the connection.
+func WithTrustedProxies(prefixes ...netip.Prefix) HTTPOption {
+	return func(o *httpOptions) {
+		o.trustedProxies = append(o.trustedProxies, prefixes...)
+	}
+}
+
+// WithQueryScrubber sets a function applied to the query parameters before they are
+// logged. Returning nil omits the query.
+func WithQueryScrubber(scrub func(url.Values) url.Values) HTTPOption {
+	return func(o *httpOptions) {
+		o.queryScrubber = scrub
+	}
+}
+
+// WithStatusLevels logs requests answered with a 5xx status at error level and 4xx at warn
+// level instead of info.
+func WithStatusLevels() HTTPOption {
+	return func(o *httpOptions) {
+		o.statusLevels = true
+	}
+}
+
+// WithSlowRequestThreshold logs requests taking at least d at warn level or above and
+// marks them with a slow field.
+func WithSlowRequestThreshold(d time.Duration) HTTPOption {
+	return func(o *httpOptions) {
+		o.slowThreshold = d
+	}
+}
+
+// WithRequestMessage replaces the message of the request log entry.
+func WithRequestMessage(message string) HTTPOption {
+	return func(o *httpOptions) {
+		o.message = message
+	}
+}
+
+// WithFieldNames renames the fields of the request log entry, keyed by their default
+// name such as "status" or "ip". Mapping a field to "" omits it.
+func WithFieldNames(names map[string]string) HTTPOption {
+	return func(o *httpOptions) {
+		if o.fieldNames == nil {
+			o.fieldNames = make(map[string]string, len(names))
+		}
+		for from, to := range names {
+			o.fieldNames[from] = to
+		}
+	}
+}
+
+// WithSkipPaths skips logging requests whose path starts with one of prefixes.
+func WithSkipPaths(prefixes ...string) HTTPOption {
+	return func(o *httpOptions) {
+		o.skipPrefixes = append(o.skipPrefixes, prefixes...)
+	}
+}
+
+// WithSkipPathPatterns skips logging requests whose path matches one of patterns.
+func WithSkipPathPatterns(patterns ...*regexp.Regexp) HTTPOption {
+	return func(o *httpOptions) {
+		o.skipPatterns = append(o.skipPatterns, patterns...)
+	}
+}
+
+// WithSkipMethods skips logging requests using one of methods.
+func WithSkipMethods(methods ...string) HTTPOption {
+	return func(o *httpOptions) {
+		if o.skipMethods == nil {
+			o.skipMethods = make(map[string]struct{}, len(methods))
+		}
+		for _, method := range methods {
+			o.skipMethods[strings.ToUpper(method)] = struct{}{}
+		}
+	}
+}
+
+// WithSkipFunc skips logging requests for which skip returns true.
+func WithSkipFunc(skip func(*http.Request) bool) HTTPOption {
+	return func(o *httpOptions) {
+		o.skipFuncs = append(o.skipFuncs, skip)
+	}
+}
+
+// WithSampling logs only one in n successful requests per chi route pattern, counting
+// requests that match no route together. Requests answered with a 4xx or 5xx status and
+// slow requests are always logged; the others carry a sampleRate field when logged.
+func WithSampling(n int) HTTPOption {
+	return func(o *httpOptions) {
+		o.sampleRate = sampleRate(n)
+	}
+}
+
+// WithCounters records the number of suppressed request log entries in c.
+func WithCounters(c *HTTPLogCounters) HTTPOption {
+	return func(o *httpOptions) {
+		if c != nil {
+			o.counters = c
+		}
+	}
+}
+
+// ParseTrustedProxies parses CIDRs, or single IP addresses, for WithTrustedProxies.
+func ParseTrustedProxies(cidrs ...string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("log: invalid trusted proxy %q: %w", cidr, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("log: invalid trusted proxy %q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// RedactQueryParams returns a query scrubber that replaces the values of the named
+// parameters, matched case-insensitively, with "REDACTED".
+func RedactQueryParams(names ...string) func(url.Values) url.Values {
+	redact := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		redact[strings.ToLower(name)] = struct{}{}
+	}
+
+	return func(values url.Values) url.Values {
+		scrubbed := make(url.Values, len(values))
+		for name, vals := range values {
+			if _, ok := redact[strings.ToLower(name)]; ok {
+				vals = []string{"REDACTED"}
+			}
+			scrubbed[name] = vals
+		}
+		return scrubbed
+	}
+}
+
+type httpField struct {
+	key   string
+	value interface{}
+}
+
+// skip reports whether the request matches a skip rule, counting it if so.
+func (o *httpOptions) skip(r *http.Request) bool {
+	if o.skipRequest(r) {
+		o.counters.skipped.Add(1)
+		return true
+	}
+	return false
+}
+
+func (o *httpOptions) skipRequest(r *http.Request) bool {
+	if _, ok := o.skipMethods[r.Method]; ok {
+		return true
+	}
+	for _, prefix := range o.skipPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	for _, pattern := range o.skipPatterns {
+		if pattern.MatchString(r.URL.Path) {
+			return true
+		}
+	}
+	for _, skip := range o.skipFuncs {
+		if skip(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// sample reports whether a served request should be logged under the sampling rules,
+// counting it if not.
+func (o *httpOptions) sample(r *http.Request, status int, duration time.Duration) bool {
+	if !o.sampled(status, duration) {
+		return true
+	}
+
+	// Requests without a route pattern share the counter stored under "", so that the
+	// number of counters is bounded by the routes of the router.
+	count, _ := o.sampleCounts.LoadOrStore(routePattern(r), new(atomic.Uint64))
+	if (count.(*atomic.Uint64).Add(1)-1)%o.sampleRate == 0 {
+		return true
+	}
+
+	o.counters.sampled.Add(1)
+	return false
+}
+
+// sampled reports whether a request answered with status after duration is subject to
+// sampling. Errors and slow requests are always logged.
+func (o *httpOptions) sampled(status int, duration time.Duration) bool {
+	return o.sampleRate > 0 && status < 400 && !o.isSlow(duration)
+}
+
+func (o *httpOptions) requestMessage(fallback string) string {
+	if o.message != "" {
+		return o.message
+	}
+	return fallback
+}
+
+func (o *httpOptions) isSlow(duration time.Duration) bool {
+	return o.slowThreshold > 0 && duration >= o.slowThreshold
+}
+
+// requestLevel picks the level of the request log entry.
+func (o *httpOptions) requestLevel(status int, duration time.Duration) Level {
+	level := logrus.InfoLevel
+	if o.statusLevels {
+		switch {
+		case status >= 500:
+			level = logrus.ErrorLevel
+		case status >= 400:
+			level = logrus.WarnLevel
+		}
+	}
+	if o.isSlow(duration) && level > logrus.WarnLevel {
+		level = logrus.WarnLevel
+	}
+	return level
+}
+
+// requestFields collects the fields logged for a served request, omitting empty ones and
+// applying the configured field names, followed by the context logging fields.
+func (o *httpOptions) requestFields(r *http.Request, ww middleware.WrapResponseWriter, duration time.Duration, extra ...httpField) []httpField {
+	fields := []httpField{
+		{"method", r.Method},
+		{"proto", r.Proto},
+		{"path", r.URL.Path},
+		{"route", routePattern(r)},
+		{"query", o.query(r)},
+		{"duration", duration},
+		{"status", ww.Status()},
+		{"size", ww.BytesWritten()},
+		{"ip", o.clientIP(r)},
+		{"userAgent", r.UserAgent()},
+		{"referer", r.Referer()},
+	}
+	if o.isSlow(duration) {
+		fields = append(fields, httpField{"slow", true})
+	}
+	if o.sampled(ww.Status(), duration) {
+		fields = append(fields, httpField{"sampleRate", o.sampleRate})
+	}
+	if state := getRequestState(r.Context()); state != nil && state.panicked.Load() {
+		fields = append(fields, httpField{"panic", true})
+	}
+	return withContextFields(r.Context(), o.finishFields(append(fields, extra...)))
+}
+
+// finishFields omits empty fields and applies the configured field names.
+func (o *httpOptions) finishFields(fields []httpField) []httpField {
+	out := fields[:0]
+	for _, f := range fields {
+		if s, ok := f.value.(string); ok && s == "" {
+			continue
+		}
+		if name, ok := o.fieldNames[f.key]; ok {
+			if name == "" {
+				continue
+			}
+			f.key = name
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func httpLogrusFields(fields []httpField) logrus.Fields {
+	out := make(logrus.Fields, len(fields))
+	for _, f := range fields {
+		out[f.key] = f.value
+	}
+	return out
+}
+
+func httpSLogAttrs(fields []httpField) []slog.Attr {
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slog.Any(f.key, f.value)
+	}
+	return attrs
+}
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
+
+func (o *httpOptions) query(r *http.Request) string {
+	if r.URL.RawQuery == "" || o.queryScrubber == nil {
+		return r.URL.RawQuery
+	}
+	values := o.queryScrubber(r.URL.Query())
+	if values == nil {
+		return ""
+	}
+	return values.Encode()
+}
+
+// clientIP resolves the client IP. Forwarding headers are only used when the connection
+// comes from a trusted proxy, in which case the right-most untrusted address is used.
+func (o *httpOptions) clientIP(r *http.Request) string {
+	remote := normalizeIP(r.RemoteAddr)
+	if !o.trusted(remote) {
+		return remote
+	}
+
+	if ips := forwardedFor(r.Header.Values("Forwarded")); len(ips) > 0 {
+		return o.rightmostUntrusted(ips)
+	}
+
+	var ips []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, ip := range strings.Split(header, ",") {
+			if ip = normalizeIP(ip); ip != "" {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	if len(ips) > 0 {
+		return o.rightmostUntrusted(ips)
+	}
+
+	if ip := normalizeIP(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return remote
+}
+
+func (o *httpOptions) trusted(ip string) bool {
+	if len(o.trustedProxies) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range o.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *httpOptions) rightmostUntrusted(ips []string) string {
+	for i := len(ips) - 1; i >= 0; i-- {
+		if !o.trusted(ips[i]) {
+			return ips[i]
+		}
+	}
+	return ips[0]
+}
+
+// forwardedFor returns the "for" addresses of RFC 7239 Forwarded headers.
+func forwardedFor(headers []string) []string {
+	var ips []string
+	for _, header := range headers {
+		for _, element := range strings.Split(header, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					if ip := normalizeIP(value); ip != "" {
+						ips = append(ips, ip)
+					}
+				}
+			}
+		}
+	}
+	return ips
+}
+
+// normalizeIP strips quotes, brackets and ports from an address.
+func normalizeIP(s string) string {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+	if strings.HasPrefix(s, "[") {
+		if end := strings.IndexByte(s, ']'); end > 0 {
+			return s[1:end]
+		}
+	}
+	if _, err := netip.ParseAddr(s); err == nil {
+		return s
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		return host
+	}
+	return s
+}
@@ -0,0 +1,301 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"time"
+
+	"github.com/go-chi/chi"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("HTTP request logging", func() {
+	Describe("client IP", func() {
+		trusted, err := ParseTrustedProxies("10.0.0.0/8", "192.168.1.1")
+		if err != nil {
+			panic(err)
+		}
+
+		table.DescribeTable("resolution",
+			func(remoteAddr string, headers map[string]string, opts []HTTPOption, expected string) {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = remoteAddr
+				for name, value := range headers {
+					r.Header.Set(name, value)
+				}
+
+				g.Expect(newHTTPOptions(opts).clientIP(r)).To(g.Equal(expected))
+			},
+			table.Entry("remote address without proxies",
+				"203.0.113.7:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"}, nil, "203.0.113.7"),
+			table.Entry("headers from an untrusted peer are ignored",
+				"203.0.113.7:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"},
+				[]HTTPOption{WithTrustedProxies(trusted...)}, "203.0.113.7"),
+			table.Entry("right-most untrusted X-Forwarded-For",
+				"10.0.0.2:1234", map[string]string{"X-Forwarded-For": "6.6.6.6, 1.1.1.1, 10.0.0.5"},
+				[]HTTPOption{WithTrustedProxies(trusted...)}, "1.1.1.1"),
+			table.Entry("X-Real-IP",
+				"192.168.1.1:1234", map[string]string{"X-Real-IP": "1.1.1.1"},
+				[]HTTPOption{WithTrustedProxies(trusted...)}, "1.1.1.1"),
+			table.Entry("Forwarded takes precedence",
+				"10.0.0.2:1234", map[string]string{
+					"Forwarded":       `for="[2001:db8::17]:4711";proto=https, for=10.0.0.9`,
+					"X-Forwarded-For": "1.1.1.1",
+				},
+				[]HTTPOption{WithTrustedProxies(trusted...)}, "2001:db8::17"),
+			table.Entry("all addresses trusted",
+				"10.0.0.2:1234", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.4"},
+				[]HTTPOption{WithTrustedProxies(trusted...)}, "10.0.0.3"),
+		)
+
+		It("should reject invalid proxies", func() {
+			_, err := ParseTrustedProxies("10.0.0.0/99")
+			g.Expect(err).To(g.HaveOccurred())
+		})
+	})
+
+	Describe("query", func() {
+		It("should scrub the query", func() {
+			r := httptest.NewRequest(http.MethodGet, "/?token=secret&page=2", nil)
+			o := newHTTPOptions([]HTTPOption{WithQueryScrubber(RedactQueryParams("TOKEN"))})
+
+			g.Expect(o.query(r)).To(g.Equal("page=2&token=REDACTED"))
+		})
+
+		It("should log the raw query by default", func() {
+			r := httptest.NewRequest(http.MethodGet, "/?b=1&a=2", nil)
+
+			g.Expect(newHTTPOptions(nil).query(r)).To(g.Equal("b=1&a=2"))
+		})
+	})
+
+	Describe("middlewares", func() {
+		var buf bytes.Buffer
+
+		BeforeEach(func() {
+			buf = bytes.Buffer{}
+		})
+
+		serve := func(mw func(http.Handler) http.Handler) {
+			router := chi.NewRouter()
+			router.Use(mw)
+			router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/users/42?expand=true", nil)
+			r.Header.Set("User-Agent", "test-agent")
+			r.Header.Set("Referer", "https://example.com")
+			router.ServeHTTP(httptest.NewRecorder(), r)
+		}
+
+		expectFields := func(out map[string]interface{}) {
+			g.Expect(out["method"]).To(g.Equal("GET"))
+			g.Expect(out["path"]).To(g.Equal("/users/42"))
+			g.Expect(out["route"]).To(g.Equal("/users/{id}"))
+			g.Expect(out["query"]).To(g.Equal("expand=true"))
+			g.Expect(out["userAgent"]).To(g.Equal("test-agent"))
+			g.Expect(out["referer"]).To(g.Equal("https://example.com"))
+			g.Expect(out["ip"]).To(g.Equal("192.0.2.1"))
+		}
+
+		It("should log request details with ServerLogger", func() {
+			previous := Default()
+			defer SetDefault(previous)
+			SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+
+			serve(ServerLogger())
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("Request Served"))
+			expectFields(b.Parsed[0])
+		})
+
+		It("should log request details with NewSLogChiMiddleware", func() {
+			serve(NewSLogChiMiddleware(slog.New(slog.NewJSONHandler(&buf, nil))))
+
+			var out map[string]interface{}
+			g.Expect(json.Unmarshal(buf.Bytes(), &out)).To(g.Succeed())
+			g.Expect(out["msg"]).To(g.Equal("HTTP Request Served"))
+			expectFields(out)
+		})
+
+		Describe("levels and messages", func() {
+			var previous *logrus.Logger
+
+			BeforeEach(func() {
+				previous = Default()
+				SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+			})
+
+			AfterEach(func() {
+				SetDefault(previous)
+			})
+
+			respond := func(mw func(http.Handler) http.Handler, status int, delay time.Duration) {
+				mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					time.Sleep(delay)
+					w.WriteHeader(status)
+				})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+
+			table.DescribeTable("ServerLogger levels",
+				func(opts []HTTPOption, status int, delay time.Duration, expected string) {
+					respond(ServerLogger(opts...), status, delay)
+
+					b := ByteLogs{Log: &buf}
+					b.Parse(nil)
+					g.Expect(b.Parsed[0]["level"]).To(g.Equal(expected))
+				},
+				table.Entry("info by default", nil, 500, time.Duration(0), "info"),
+				table.Entry("5xx", []HTTPOption{WithStatusLevels()}, 503, time.Duration(0), "error"),
+				table.Entry("4xx", []HTTPOption{WithStatusLevels()}, 404, time.Duration(0), "warning"),
+				table.Entry("2xx", []HTTPOption{WithStatusLevels()}, 200, time.Duration(0), "info"),
+				table.Entry("slow", []HTTPOption{WithSlowRequestThreshold(time.Millisecond)}, 200, 2*time.Millisecond, "warning"),
+				table.Entry("slow 5xx", []HTTPOption{WithStatusLevels(), WithSlowRequestThreshold(time.Millisecond)}, 500, 2*time.Millisecond, "error"),
+			)
+
+			It("should mark slow requests", func() {
+				respond(ServerLogger(WithSlowRequestThreshold(time.Millisecond)), 200, 2*time.Millisecond)
+
+				b := ByteLogs{Log: &buf}
+				b.Parse(nil)
+				g.Expect(b.Parsed[0]["slow"]).To(g.Equal(true))
+			})
+
+			It("should use status levels in NewSLogChiMiddleware", func() {
+				respond(NewSLogChiMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)), WithStatusLevels()), 502, 0)
+
+				var out map[string]interface{}
+				g.Expect(json.Unmarshal(buf.Bytes(), &out)).To(g.Succeed())
+				g.Expect(out["level"]).To(g.Equal("ERROR"))
+			})
+
+			It("should use a custom message and field names", func() {
+				opts := []HTTPOption{
+					WithRequestMessage("request"),
+					WithFieldNames(map[string]string{"status": "http.status", "proto": "", "requestID": "request_id"}),
+				}
+				respond(ServerLogger(opts...), 200, 0)
+				respond(NewSLogChiMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)), opts...), 200, 0)
+
+				b := ByteLogs{Log: &buf}
+				b.Parse(nil)
+				g.Expect(b.Parsed).To(g.HaveLen(2))
+				for _, out := range b.Parsed {
+					g.Expect(out["msg"]).To(g.Equal("request"))
+					g.Expect(out["http.status"]).To(g.BeNumerically("==", 200))
+					g.Expect(out).ToNot(g.HaveKey("status"))
+					g.Expect(out).ToNot(g.HaveKey("proto"))
+				}
+			})
+		})
+
+		Describe("skip and sample rules", func() {
+			var (
+				counters *HTTPLogCounters
+				logger   *slog.Logger
+			)
+
+			BeforeEach(func() {
+				counters = &HTTPLogCounters{}
+				logger = slog.New(slog.NewJSONHandler(&buf, nil))
+			})
+
+			request := func(mw func(http.Handler) http.Handler, method, target string, status int) {
+				router := chi.NewRouter()
+				router.Use(mw)
+				router.HandleFunc("/*", func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(status)
+				})
+				router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+			}
+
+			lines := func() []map[string]interface{} {
+				b := ByteLogs{Log: &buf}
+				b.Parse(nil)
+				return b.Parsed
+			}
+
+			It("should skip matching requests", func() {
+				mw := NewSLogChiMiddleware(logger,
+					WithCounters(counters),
+					WithSkipPaths("/health"),
+					WithSkipPathPatterns(regexp.MustCompile(`^/metrics$`)),
+					WithSkipMethods("options"),
+					WithSkipFunc(func(r *http.Request) bool { return r.Header.Get("X-Probe") != "" }),
+				)
+
+				request(mw, http.MethodGet, "/healthz", 200)
+				request(mw, http.MethodGet, "/metrics", 200)
+				request(mw, http.MethodOptions, "/users", 200)
+				request(mw, http.MethodGet, "/users", 200)
+
+				probe := httptest.NewRequest(http.MethodGet, "/users", nil)
+				probe.Header.Set("X-Probe", "1")
+				mw(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), probe)
+
+				g.Expect(lines()).To(g.HaveLen(1))
+				g.Expect(lines()[0]["path"]).To(g.Equal("/users"))
+				g.Expect(counters.Skipped()).To(g.BeNumerically("==", 4))
+			})
+
+			It("should sample successful requests per route", func() {
+				mw := NewSLogChiMiddleware(logger, WithCounters(counters), WithSampling(3))
+
+				for i := 0; i < 6; i++ {
+					request(mw, http.MethodGet, "/a", 200)
+				}
+				request(mw, http.MethodGet, "/b", 200)
+
+				g.Expect(lines()).To(g.HaveLen(3))
+				g.Expect(lines()[0]["sampleRate"]).To(g.BeNumerically("==", 3))
+				g.Expect(counters.Sampled()).To(g.BeNumerically("==", 4))
+			})
+
+			It("should sample requests without a route pattern together", func() {
+				mw := NewSLogChiMiddleware(logger, WithCounters(counters), WithSampling(3))
+				handler := mw(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+				for _, path := range []string{"/a", "/b", "/c", "/d"} {
+					handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+				}
+
+				g.Expect(lines()).To(g.HaveLen(2))
+				g.Expect(counters.Sampled()).To(g.BeNumerically("==", 2))
+			})
+
+			It("should always log errors and slow requests", func() {
+				previous := Default()
+				defer SetDefault(previous)
+				SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+				mw := ServerLogger(WithCounters(counters), WithSampling(100), WithSlowRequestThreshold(time.Millisecond))
+
+				request(mw, http.MethodGet, "/a", 200)
+				request(mw, http.MethodGet, "/a", 500)
+				request(mw, http.MethodGet, "/a", 404)
+				request(mw, http.MethodGet, "/a", 200)
+				mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					time.Sleep(2 * time.Millisecond)
+				})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+				logs := lines()
+				g.Expect(logs).To(g.HaveLen(4))
+				g.Expect(counters.Sampled()).To(g.BeNumerically("==", 1))
+				g.Expect(logs[0]["sampleRate"]).To(g.BeNumerically("==", 100))
+				for _, entry := range logs[1:] {
+					g.Expect(entry).ToNot(g.HaveKey("sampleRate"))
+				}
+				g.Expect(logs[1]["status"]).To(g.BeNumerically("==", 500))
+			})
+		})
+	})
+})
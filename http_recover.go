@@ -0,0 +1,76 @@
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// Recoverer is a middleware that recovers from panics in later handlers, logs them at
+// error level with the stack trace, the request details and the context logging fields,
+// and responds with a 500. Mount it after ServerLogger so the request entry records the
+// 500 and a panic field.
+func Recoverer(opts ...HTTPOption) func(next http.Handler) http.Handler {
+	return recoverer(newHTTPOptions(opts), func(r *http.Request, fields []httpField) {
+		Default().WithFields(httpLogrusFields(fields)).WithContext(r.Context()).Error("Panic Recovered")
+	})
+}
+
+// NewSLogRecoverer is the slog flavor of Recoverer. If `l` is nil, it uses the default
+// logger.
+func NewSLogRecoverer(l *slog.Logger, opts ...HTTPOption) func(next http.Handler) http.Handler {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	return recoverer(newHTTPOptions(opts), func(r *http.Request, fields []httpField) {
+		l.LogAttrs(r.Context(), slog.LevelError, "Panic Recovered", httpSLogAttrs(fields)...)
+	})
+}
+
+func recoverer(o *httpOptions, logPanic func(*http.Request, []httpField)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+
+				if state := getRequestState(r.Context()); state != nil {
+					state.panicked.Store(true)
+				}
+
+				fields := o.panicFields(r, p, debug.Stack())
+				logPanic(r, fields)
+
+				if ww, ok := w.(middleware.WrapResponseWriter); !ok || ww.Status() == 0 {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// panicFields collects the fields logged for a recovered panic.
+func (o *httpOptions) panicFields(r *http.Request, p interface{}, stack []byte) []httpField {
+	fields := []httpField{
+		{"panic", fmt.Sprint(p)},
+		{"stack", string(stack)},
+		{"method", r.Method},
+		{"path", r.URL.Path},
+		{"route", routePattern(r)},
+		{"query", o.query(r)},
+		{"ip", o.clientIP(r)},
+		{"userAgent", r.UserAgent()},
+	}
+	return withContextFields(r.Context(), o.finishFields(fields))
+}
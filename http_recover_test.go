@@ -0,0 +1,112 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Recoverer", func() {
+	var (
+		buf      bytes.Buffer
+		previous *logrus.Logger
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		previous = Default()
+		SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+	})
+
+	AfterEach(func() {
+		SetDefault(previous)
+	})
+
+	serve := func(middlewares ...func(http.Handler) http.Handler) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		router.Use(middleware.RequestID)
+		router.Use(middlewares...)
+		router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			PushContextFields(r.Context(), MakeField("user", "u1"))
+			panic("boom")
+		})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		return rec
+	}
+
+	withFields := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithContext(r.Context())))
+		})
+	}
+
+	lines := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	It("should log the panic and mark the request entry", func() {
+		rec := serve(ServerLogger(WithStatusLevels()), withFields, Recoverer())
+
+		g.Expect(rec.Code).To(g.Equal(http.StatusInternalServerError))
+
+		logs := lines()
+		g.Expect(logs).To(g.HaveLen(2))
+
+		g.Expect(logs[0]["msg"]).To(g.Equal("Panic Recovered"))
+		g.Expect(logs[0]["level"]).To(g.Equal("error"))
+		g.Expect(logs[0]["panic"]).To(g.Equal("boom"))
+		g.Expect(logs[0]["stack"]).To(g.ContainSubstring("http_recover_test.go"))
+		g.Expect(logs[0]["route"]).To(g.Equal("/users/{id}"))
+		g.Expect(logs[0]["user"]).To(g.Equal("u1"))
+		g.Expect(logs[0]["requestID"]).ToNot(g.BeEmpty())
+
+		g.Expect(logs[1]["msg"]).To(g.Equal("Request Served"))
+		g.Expect(logs[1]["level"]).To(g.Equal("error"))
+		g.Expect(logs[1]["status"]).To(g.BeNumerically("==", 500))
+		g.Expect(logs[1]["panic"]).To(g.Equal(true))
+		g.Expect(logs[1]["requestID"]).To(g.Equal(logs[0]["requestID"]))
+	})
+
+	It("should work with the slog middlewares", func() {
+		l := slog.New(slog.NewJSONHandler(&buf, nil))
+		rec := serve(NewSLogChiMiddleware(l), withFields, NewSLogRecoverer(l))
+
+		g.Expect(rec.Code).To(g.Equal(http.StatusInternalServerError))
+
+		logs := lines()
+		g.Expect(logs).To(g.HaveLen(2))
+		g.Expect(logs[0]["msg"]).To(g.Equal("Panic Recovered"))
+		g.Expect(logs[0]["level"]).To(g.Equal("ERROR"))
+		g.Expect(logs[1]["status"]).To(g.BeNumerically("==", 500))
+		g.Expect(logs[1]["panic"]).To(g.Equal(true))
+	})
+
+	It("should not mark requests that did not panic", func() {
+		mw := ServerLogger()
+		mw(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		g.Expect(lines()[0]).ToNot(g.HaveKey("panic"))
+	})
+
+	It("should re-panic http.ErrAbortHandler", func() {
+		handler := Recoverer()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		g.Expect(func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(context.Background()))
+		}).To(g.PanicWith(http.ErrAbortHandler))
+	})
+})
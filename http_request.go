@@ -0,0 +1,104 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+)
+
+type requestStateKey struct{}
+
+// requestState is shared between the request logging middlewares, Recoverer and
+// FromRequest through the request context.
+type requestState struct {
+	panicked atomic.Bool
+	logger   FieldLogger
+}
+
+func withRequestState(r *http.Request) (*http.Request, *requestState) {
+	if state := getRequestState(r.Context()); state != nil {
+		return r, state
+	}
+	state := &requestState{}
+	return r.WithContext(context.WithValue(r.Context(), requestStateKey{}, state)), state
+}
+
+func getRequestState(ctx context.Context) *requestState {
+	state, _ := ctx.Value(requestStateKey{}).(*requestState)
+	return state
+}
+
+// FromRequest returns the request-scoped logger stored by ServerLogger or
+// NewSLogChiMiddleware. It carries the request ID, method, route and client IP along with
+// any fields the handler adds to r.Context() with WithContext or PushContextFields, which
+// update the stack of the request in place. Fields added with ContextWithFields only reach
+// loggers built from the derived context, not this logger or the final request entry.
+// Without either middleware it falls back to FromContext.
+func FromRequest(r *http.Request) FieldLogger {
+	if state := getRequestState(r.Context()); state != nil && state.logger != nil {
+		return state.logger
+	}
+	return FromContext(r.Context())
+}
+
+// prepareRequest picks up the W3C trace context, initializes the request state and the
+// context logging fields, and stores the request-scoped logger built by newLogger.
+func (o *httpOptions) prepareRequest(r *http.Request, newLogger func(context.Context) FieldLogger) *http.Request {
+	r, state := withRequestState(extractTraceparent(r))
+
+	fields := []httpField{
+		{"requestID", middleware.GetReqID(r.Context())},
+		{"method", r.Method},
+		{"ip", o.clientIP(r)},
+	}
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		fields = append(fields, httpField{"route", routeField{rctx}})
+	}
+
+	var stack []Field
+	for _, f := range o.finishFields(fields) {
+		stack = append(stack, MakeField(f.key, f.value))
+	}
+	r = r.WithContext(ContextWithFields(r.Context(), stack...))
+
+	state.logger = newLogger(r.Context())
+	return r
+}
+
+// withContextFields appends the context logging fields of ctx whose keys are not already
+// used by fields.
+func withContextFields(ctx context.Context, fields []httpField) []httpField {
+	used := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		used[f.key] = struct{}{}
+	}
+	for _, attr := range fieldsToAttrs(contextFields(ctx)) {
+		if _, ok := used[attr.Key]; !ok {
+			fields = append(fields, httpField{attr.Key, attr.Value.Any()})
+		}
+	}
+	return fields
+}
+
+// routeField resolves to the chi route pattern matched so far when it is logged, since
+// routing has not happened yet when the middlewares run.
+type routeField struct {
+	rctx *chi.Context
+}
+
+func (f routeField) String() string {
+	return f.rctx.RoutePattern()
+}
+
+func (f routeField) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+func (f routeField) LogValue() slog.Value {
+	return slog.StringValue(f.String())
+}
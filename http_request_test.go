@@ -0,0 +1,110 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Request-scoped logging", func() {
+	var (
+		buf      bytes.Buffer
+		previous *logrus.Logger
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		previous = Default()
+		SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+	})
+
+	AfterEach(func() {
+		SetDefault(previous)
+	})
+
+	serve := func(mw func(http.Handler) http.Handler) []map[string]interface{} {
+		router := chi.NewRouter()
+		router.Use(middleware.RequestID, mw)
+		router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			PushContextFields(r.Context(), MakeField("user", chi.URLParam(r, "id")))
+			FromRequest(r).Info("handling")
+		})
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	expectRequestFields := func(logs []map[string]interface{}) {
+		g.Expect(logs).To(g.HaveLen(2))
+		g.Expect(logs[0]["msg"]).To(g.Equal("handling"))
+		g.Expect(logs[1]["requestID"]).To(g.Equal(logs[0]["requestID"]))
+		for _, entry := range logs {
+			g.Expect(entry["requestID"]).ToNot(g.BeEmpty())
+			g.Expect(entry["method"]).To(g.Equal("GET"))
+			g.Expect(entry["route"]).To(g.Equal("/users/{id}"))
+			g.Expect(entry["ip"]).To(g.Equal("192.0.2.1"))
+			g.Expect(entry["user"]).To(g.Equal("42"))
+		}
+	}
+
+	It("should provide a request-scoped logger with ServerLogger", func() {
+		logs := serve(ServerLogger())
+
+		expectRequestFields(logs)
+		g.Expect(logs[1]["msg"]).To(g.Equal("Request Served"))
+	})
+
+	It("should provide a request-scoped logger with NewSLogChiMiddleware", func() {
+		logs := serve(NewSLogChiMiddleware(slog.New(slog.NewJSONHandler(&buf, nil))))
+
+		expectRequestFields(logs)
+		g.Expect(logs[1]["msg"]).To(g.Equal("HTTP Request Served"))
+		g.Expect(bytes.Count(buf.Bytes(), []byte(`"method"`))).To(g.Equal(2))
+	})
+
+	It("should use the configured field names in the context", func() {
+		logs := serve(ServerLogger(WithFieldNames(map[string]string{"ip": "clientIP"})))
+
+		g.Expect(logs[0]["clientIP"]).To(g.Equal("192.0.2.1"))
+		g.Expect(logs[0]).ToNot(g.HaveKey("ip"))
+	})
+
+	It("should only log fields added to the request context in place", func() {
+		router := chi.NewRouter()
+		router.Use(ServerLogger())
+		router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			WithContext(r.Context(), MakeField("user", "u1"))
+			ctx := ContextWithFields(r.Context(), MakeField("job", "j1"))
+			FromContext(ctx).Info("handling")
+		})
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		g.Expect(b.Parsed).To(g.HaveLen(2))
+		g.Expect(b.Parsed[0]["user"]).To(g.Equal("u1"))
+		g.Expect(b.Parsed[0]["job"]).To(g.Equal("j1"))
+		g.Expect(b.Parsed[1]["user"]).To(g.Equal("u1"))
+		g.Expect(b.Parsed[1]).ToNot(g.HaveKey("job"))
+	})
+
+	It("should fall back to the context without the middleware", func() {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r = r.WithContext(WithContext(r.Context(), MakeField("user", "u1")))
+
+		FromRequest(r).Info("plain")
+
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		g.Expect(b.Parsed[0]["user"]).To(g.Equal("u1"))
+	})
+})
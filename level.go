@@ -0,0 +1,137 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Level is the severity of a log entry.
+type Level = logrus.Level
+
+// ParseLevel converts a level name into a Level. Matching is case-insensitive and accepts
+// common aliases such as "warning" and "err". Unknown names return an error.
+func ParseLevel(logLevel string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(logLevel)) {
+	case "panic":
+		return logrus.PanicLevel, nil
+	case "fatal", "critical", "crit":
+		return logrus.FatalLevel, nil
+	case "error", "err":
+		return logrus.ErrorLevel, nil
+	case "warn", "warning":
+		return logrus.WarnLevel, nil
+	case "info", "information":
+		return logrus.InfoLevel, nil
+	case "debug", "dbg":
+		return logrus.DebugLevel, nil
+	case "trace":
+		return logrus.TraceLevel, nil
+	}
+
+	return logrus.InfoLevel, fmt.Errorf("log: unknown level %q", logLevel)
+}
+
+// getLevel parses logLevel, falling back to info and warning on l when it is not valid.
+func getLevel(l *logrus.Logger, logLevel string) Level {
+	level, err := ParseLevel(logLevel)
+	if err != nil && l != nil {
+		l.WithField("level", logLevel).Warn("invalid log level; falling back to info")
+	}
+	return level
+}
+
+// LevelVar holds a Level that can be changed at runtime. It is safe for concurrent use and
+// keeps every logger attached to it in sync.
+type LevelVar struct {
+	mu       sync.Mutex
+	level    atomic.Uint32
+	base     Level
+	loggers  []*logrus.Logger
+	revert   *time.Timer
+	revertAt time.Time
+}
+
+// NewLevelVar creates a LevelVar starting at level.
+func NewLevelVar(level Level) *LevelVar {
+	v := &LevelVar{base: level}
+	v.level.Store(uint32(level))
+	return v
+}
+
+// Level returns the current level.
+func (v *LevelVar) Level() Level {
+	return Level(v.level.Load())
+}
+
+// RevertAt returns when a temporary level set with SetFor expires, or the zero time if
+// none is pending.
+func (v *LevelVar) RevertAt() time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.revertAt
+}
+
+// Set changes the level and cancels any pending revert.
+func (v *LevelVar) Set(level Level) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.stopRevert()
+	v.base = level
+	v.apply(level)
+}
+
+// SetFor changes the level for d, after which it reverts to the level that was set before
+// the first temporary change. A non-positive d behaves like Set.
+func (v *LevelVar) SetFor(level Level, d time.Duration) {
+	if d <= 0 {
+		v.Set(level)
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.stopRevert()
+	v.apply(level)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if v.revert != timer {
+			return
+		}
+		v.revert = nil
+		v.revertAt = time.Time{}
+		v.apply(v.base)
+	})
+	v.revert = timer
+	v.revertAt = time.Now().Add(d)
+}
+
+// Attach makes l follow the level held by v.
+func (v *LevelVar) Attach(l *logrus.Logger) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.loggers = append(v.loggers, l)
+	l.SetLevel(v.Level())
+}
+
+func (v *LevelVar) apply(level Level) {
+	v.level.Store(uint32(level))
+	for _, l := range v.loggers {
+		l.SetLevel(level)
+	}
+}
+
+func (v *LevelVar) stopRevert() {
+	if v.revert != nil {
+		v.revert.Stop()
+		v.revert = nil
+		v.revertAt = time.Time{}
+	}
+}
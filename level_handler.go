@@ -0,0 +1,70 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type levelPayload struct {
+	Level    string     `json:"level"`
+	Duration string     `json:"duration,omitempty"`
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+type levelErrorPayload struct {
+	Error string `json:"error"`
+}
+
+// NewLevelHandler returns an http.Handler that reads and changes the level held by v.
+//
+// GET responds with the current level. PUT accepts a JSON body such as
+// {"level": "debug", "duration": "10m"}; the optional duration makes the change revert
+// automatically once it elapses. The handler can be mounted on a chi router, e.g.
+// r.Handle("/log/level", log.NewLevelHandler(v)).
+func NewLevelHandler(v *LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: "invalid request body"})
+				return
+			}
+
+			level, err := ParseLevel(req.Level)
+			if err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: err.Error()})
+				return
+			}
+
+			var d time.Duration
+			if req.Duration != "" {
+				d, err = time.ParseDuration(req.Duration)
+				if err != nil || d < 0 {
+					writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: "invalid duration"})
+					return
+				}
+			}
+
+			v.SetFor(level, d)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeLevelJSON(w, http.StatusMethodNotAllowed, levelErrorPayload{Error: "method not allowed"})
+			return
+		}
+
+		res := levelPayload{Level: v.Level().String()}
+		if revertAt := v.RevertAt(); !revertAt.IsZero() {
+			res.RevertAt = &revertAt
+		}
+		writeLevelJSON(w, http.StatusOK, res)
+	})
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
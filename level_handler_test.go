@@ -0,0 +1,88 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("LevelHandler", func() {
+	var (
+		v       *LevelVar
+		l       *logrus.Logger
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		v = NewLevelVar(logrus.InfoLevel)
+		l = NewLogger(WithLevelVar(v))
+		handler = NewLevelHandler(v)
+	})
+
+	serve := func(method, body string) (*httptest.ResponseRecorder, levelPayload) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/log/level", strings.NewReader(body)))
+
+		var res levelPayload
+		json.Unmarshal(rec.Body.Bytes(), &res)
+		return rec, res
+	}
+
+	It("should return the current level", func() {
+		rec, res := serve(http.MethodGet, "")
+
+		g.Expect(rec.Code).To(g.Equal(http.StatusOK))
+		g.Expect(res.Level).To(g.Equal("info"))
+		g.Expect(res.RevertAt).To(g.BeNil())
+	})
+
+	It("should change the level of attached loggers", func() {
+		rec, res := serve(http.MethodPut, `{"level": "debug"}`)
+
+		g.Expect(rec.Code).To(g.Equal(http.StatusOK))
+		g.Expect(res.Level).To(g.Equal("debug"))
+		g.Expect(l.GetLevel()).To(g.Equal(logrus.DebugLevel))
+	})
+
+	It("should revert a temporary change", func() {
+		rec, res := serve(http.MethodPut, `{"level": "debug", "duration": "20ms"}`)
+
+		g.Expect(rec.Code).To(g.Equal(http.StatusOK))
+		g.Expect(res.RevertAt).ToNot(g.BeNil())
+		g.Expect(l.GetLevel()).To(g.Equal(logrus.DebugLevel))
+
+		g.Eventually(l.GetLevel).Should(g.Equal(logrus.InfoLevel))
+		g.Expect(v.RevertAt().IsZero()).To(g.BeTrue())
+	})
+
+	It("should cancel a pending revert when the level is set permanently", func() {
+		v.SetFor(logrus.TraceLevel, 20*time.Millisecond)
+		serve(http.MethodPut, `{"level": "error"}`)
+
+		g.Consistently(l.GetLevel, 50*time.Millisecond).Should(g.Equal(logrus.ErrorLevel))
+	})
+
+	It("should reject invalid input", func() {
+		rec, _ := serve(http.MethodPut, `{"level": "loud"}`)
+		g.Expect(rec.Code).To(g.Equal(http.StatusBadRequest))
+
+		rec, _ = serve(http.MethodPut, `{"level": "debug", "duration": "soon"}`)
+		g.Expect(rec.Code).To(g.Equal(http.StatusBadRequest))
+
+		rec, _ = serve(http.MethodPut, `not json`)
+		g.Expect(rec.Code).To(g.Equal(http.StatusBadRequest))
+
+		g.Expect(v.Level()).To(g.Equal(logrus.InfoLevel))
+	})
+
+	It("should reject other methods", func() {
+		rec, _ := serve(http.MethodPost, `{"level": "debug"}`)
+		g.Expect(rec.Code).To(g.Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"strings"
+	"sync"
+)
+
+var componentLevels = NewLevelRegistry()
+
+// ComponentLevels returns the registry consulted by prefixed loggers created with
+// NewPrefixedLogger.
+func ComponentLevels() *LevelRegistry {
+	return componentLevels
+}
+
+// LevelRegistry holds log levels keyed by PrefixedLogger prefix. Patterns are either an
+// exact prefix such as "task-consumer.sqs" or a hierarchical wildcard such as
+// "task-consumer.*", which matches "task-consumer" and every prefix nested below it. The
+// most specific pattern wins; "*" matches every prefix.
+//
+// A component level may be more verbose than the level of the underlying logger when it
+// was built with New or NewLogger and its output was not replaced since. Such entries are
+// written through a second logger sharing its output, formatter and the hooks it had when
+// the first of them was logged. Otherwise they are dropped.
+type LevelRegistry struct {
+	mu     sync.RWMutex
+	levels map[string]Level
+}
+
+// NewLevelRegistry creates an empty LevelRegistry.
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{levels: make(map[string]Level)}
+}
+
+// Set configures the level for pattern.
+func (r *LevelRegistry) Set(pattern string, level Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.levels[pattern] = level
+}
+
+// Unset removes the level configured for pattern.
+func (r *LevelRegistry) Unset(pattern string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.levels, pattern)
+}
+
+// Reset removes every configured level.
+func (r *LevelRegistry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.levels = make(map[string]Level)
+}
+
+// Lookup returns the level of the most specific pattern matching prefix. ok is false when
+// no pattern matches.
+func (r *LevelRegistry) Lookup(prefix string) (level Level, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.levels) == 0 {
+		return level, false
+	}
+
+	if level, ok = r.levels[prefix]; ok {
+		return level, true
+	}
+
+	for name := prefix; ; {
+		if level, ok = r.levels[name+".*"]; ok {
+			return level, true
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+
+	level, ok = r.levels["*"]
+	return level, ok
+}
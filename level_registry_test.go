@@ -0,0 +1,73 @@
+package log
+
+import (
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("LevelRegistry", func() {
+	var r *LevelRegistry
+
+	BeforeEach(func() {
+		r = NewLevelRegistry()
+	})
+
+	It("should not match when empty", func() {
+		_, ok := r.Lookup("task-consumer")
+		g.Expect(ok).To(g.BeFalse())
+	})
+
+	It("should match exact prefixes", func() {
+		r.Set("task-consumer", logrus.DebugLevel)
+
+		level, ok := r.Lookup("task-consumer")
+		g.Expect(ok).To(g.BeTrue())
+		g.Expect(level).To(g.Equal(logrus.DebugLevel))
+
+		_, ok = r.Lookup("task-consumer.sqs")
+		g.Expect(ok).To(g.BeFalse())
+	})
+
+	It("should match wildcards hierarchically", func() {
+		r.Set("task-consumer.*", logrus.DebugLevel)
+		r.Set("task-consumer.sqs.*", logrus.TraceLevel)
+
+		level, _ := r.Lookup("task-consumer")
+		g.Expect(level).To(g.Equal(logrus.DebugLevel))
+
+		level, _ = r.Lookup("task-consumer.http")
+		g.Expect(level).To(g.Equal(logrus.DebugLevel))
+
+		level, _ = r.Lookup("task-consumer.sqs.poller")
+		g.Expect(level).To(g.Equal(logrus.TraceLevel))
+
+		_, ok := r.Lookup("task-consumerx")
+		g.Expect(ok).To(g.BeFalse())
+	})
+
+	It("should prefer exact matches and fall back to the catch all", func() {
+		r.Set("*", logrus.WarnLevel)
+		r.Set("api.*", logrus.DebugLevel)
+		r.Set("api.health", logrus.ErrorLevel)
+
+		level, _ := r.Lookup("api.health")
+		g.Expect(level).To(g.Equal(logrus.ErrorLevel))
+
+		level, _ = r.Lookup("worker")
+		g.Expect(level).To(g.Equal(logrus.WarnLevel))
+	})
+
+	It("should unset and reset levels", func() {
+		r.Set("api", logrus.DebugLevel)
+		r.Set("worker", logrus.DebugLevel)
+
+		r.Unset("api")
+		_, ok := r.Lookup("api")
+		g.Expect(ok).To(g.BeFalse())
+
+		r.Reset()
+		_, ok = r.Lookup("worker")
+		g.Expect(ok).To(g.BeFalse())
+	})
+})
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Level", func() {
+	Describe("ParseLevel", func() {
+		table.DescribeTable("valid levels",
+			func(name string, expected Level) {
+				level, err := ParseLevel(name)
+				g.Expect(err).ToNot(g.HaveOccurred())
+				g.Expect(level).To(g.Equal(expected))
+			},
+			table.Entry("trace", "trace", logrus.TraceLevel),
+			table.Entry("debug", "debug", logrus.DebugLevel),
+			table.Entry("info", "info", logrus.InfoLevel),
+			table.Entry("upper case", "WARN", logrus.WarnLevel),
+			table.Entry("warning alias", "warning", logrus.WarnLevel),
+			table.Entry("err alias", "err", logrus.ErrorLevel),
+			table.Entry("padded", " error ", logrus.ErrorLevel),
+			table.Entry("fatal", "Fatal", logrus.FatalLevel),
+			table.Entry("panic", "panic", logrus.PanicLevel),
+		)
+
+		It("should reject unknown levels", func() {
+			_, err := ParseLevel("verbose")
+			g.Expect(err).To(g.MatchError(`log: unknown level "verbose"`))
+		})
+	})
+
+	Describe("New", func() {
+		var previous *logrus.Logger
+
+		BeforeEach(func() {
+			previous = Default()
+		})
+
+		AfterEach(func() {
+			SetDefault(previous)
+		})
+
+		It("should accept trace", func() {
+			l := New(true, "trace")
+			g.Expect(l.GetLevel()).To(g.Equal(logrus.TraceLevel))
+		})
+
+		It("should fall back to info and warn on an unknown level", func() {
+			buf := bytes.Buffer{}
+			l := NewLogger(WithJSONFormat(), WithOutput(&buf))
+
+			g.Expect(getLevel(l, "loud")).To(g.Equal(logrus.InfoLevel))
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.LogInLogs("level", "warning")).To(g.BeTrue())
+			g.Expect(b.LogInLogs("msg", "invalid log level; falling back to info")).To(g.BeTrue())
+		})
+	})
+})
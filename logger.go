@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/middleware"
@@ -32,119 +33,163 @@ type Logger interface {
 	Writer() *io.PipeWriter
 }
 
-var (
-	logger *logrus.Logger
-)
+// FieldLogger is a Logger that also supports structured fields and contexts. Use
+// NewFieldLogger to get one backed by logrus.
+type FieldLogger interface {
+	Logger
+	WithField(key string, value interface{}) FieldLogger
+	WithFields(fields Fields) FieldLogger
+	WithError(err error) FieldLogger
+	WithContext(ctx context.Context) FieldLogger
+	InfoWithFields(fields Fields, args ...interface{})
+	InfoWithFieldsf(fields Fields, message string, args ...interface{})
+	DebugWithFields(fields Fields, args ...interface{})
+	DebugWithFieldsf(fields Fields, message string, args ...interface{})
+	ErrorWithFields(fields Fields, args ...interface{})
+	ErrorWithFieldsf(fields Fields, message string, args ...interface{})
+	WarnWithFields(fields Fields, args ...interface{})
+	WarnWithFieldsf(fields Fields, message string, args ...interface{})
+	FatalWithFields(fields Fields, args ...interface{})
+	FatalWithFieldsf(fields Fields, message string, args ...interface{})
+	PanicWithFields(fields Fields, args ...interface{})
+	PanicWithFieldsf(fields Fields, message string, args ...interface{})
+}
+
+var defaultLogger atomic.Pointer[logrus.Logger]
 
 func init() {
 	New(false, "info")
 }
 
-// New - Creates a new instance of logrus with customized configuration
+// New - Creates a new instance of logrus with customized configuration and makes it the
+// package default. Use NewLogger to build a logger without replacing the default.
+//
+// An unknown logLevel falls back to info and a warning is logged; use ParseLevel to
+// validate the level up front.
 func New(isJSONFormatted bool, logLevel string) *logrus.Logger {
-	var formatter logrus.Formatter
-
-	formatter = &logrus.TextFormatter{
-		ForceColors:            true,
-		DisableLevelTruncation: true,
+	var opts []Option
+	if isJSONFormatted {
+		opts = append(opts, WithJSONFormat())
 	}
 
-	if isJSONFormatted {
-		formatter = &logrus.JSONFormatter{}
+	log := NewLogger(opts...)
+	log.SetLevel(getLevel(log, logLevel))
+	SetDefault(log)
+	return log
+}
+
+// NewLogger creates a standalone instance of logrus configured with the given options.
+// Unlike New, it does not change the package default logger.
+func NewLogger(opts ...Option) *logrus.Logger {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
 	}
+
 	log := logrus.New()
-	log.SetFormatter(formatter)
-	log.SetLevel(getLevel(logLevel))
+	log.SetFormatter(o.buildFormatter())
+	log.SetLevel(o.level)
+	if o.levelVar != nil {
+		o.levelVar.Attach(log)
+	}
+	log.SetReportCaller(o.reportCaller)
+	if o.out != nil {
+		log.SetOutput(o.out)
+	}
+	log.AddHook(ContextHook{})
+	for _, hook := range o.hooks {
+		log.AddHook(hook)
+	}
+	if o.slogHandler != nil {
+		log.SetFormatter(discardFormatter{})
+		log.SetOutput(io.Discard)
+		log.AddHook(slogHook{handler: o.slogHandler})
+	}
+	log.SetOutput(&sharedOutput{out: log.Out})
 
-	logger = log
 	return log
 }
 
+// Default returns the logger used by the package level logging functions.
+func Default() *logrus.Logger {
+	return defaultLogger.Load()
+}
+
+// SetDefault replaces the logger used by the package level logging functions. A nil
+// logger is ignored.
+func SetDefault(l *logrus.Logger) {
+	if l == nil {
+		return
+	}
+	defaultLogger.Store(l)
+}
+
 func GetLogger() Logger {
-	return logger
+	return Default()
 }
 
 // RequestLogger creates a logger with the request ID on it
 func RequestLogger(ctx context.Context) Logger {
-	return logger.WithFields(logrus.Fields{
+	return Default().WithFields(logrus.Fields{
 		"requestID": middleware.GetReqID(ctx),
 	})
 }
 
 func Writer() *io.PipeWriter {
-	return logger.Writer()
+	return Default().Writer()
 }
 
 func WriterLevel(logLevel string) *io.PipeWriter {
-	return logger.WriterLevel(getLevel(logLevel))
-}
-
-func getLevel(logLevel string) logrus.Level {
-	level := logrus.InfoLevel
-	switch logLevel {
-	case "panic":
-		level = logrus.PanicLevel
-	case "fatal":
-		level = logrus.FatalLevel
-	case "error":
-		level = logrus.ErrorLevel
-	case "warn":
-		level = logrus.WarnLevel
-	case "info":
-		level = logrus.InfoLevel
-	case "debug":
-		level = logrus.DebugLevel
-	}
-
-	return level
+	l := Default()
+	return l.WriterLevel(getLevel(l, logLevel))
 }
 
 func Info(args ...interface{}) {
-	logger.Info(args...)
+	Default().Info(args...)
 }
 
 func Infof(message string, args ...interface{}) {
-	logger.Infof(message, args...)
+	Default().Infof(message, args...)
 }
 
 func InfoWithFields(fields Fields, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Info(args...)
+	Default().WithFields(logrus.Fields(fields)).Info(args...)
 }
 
 func InfoWithFieldsf(fields Fields, message string, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Infof(message, args...)
+	Default().WithFields(logrus.Fields(fields)).Infof(message, args...)
 }
 
 func Debug(args ...interface{}) {
-	logger.Debug(args...)
+	Default().Debug(args...)
 }
 
 func Debugf(message string, args ...interface{}) {
-	logger.Debugf(message, args...)
+	Default().Debugf(message, args...)
 }
 
 func DebugWithFields(fields Fields, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Debug(args...)
+	Default().WithFields(logrus.Fields(fields)).Debug(args...)
 }
 
 func DebugWithFieldsf(fields Fields, message string, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Debugf(message, args...)
+	Default().WithFields(logrus.Fields(fields)).Debugf(message, args...)
 }
 
 func Error(args ...interface{}) {
-	logger.Error(args...)
+	Default().Error(args...)
 }
 
 func Errorf(message string, args ...interface{}) {
-	logger.Errorf(message, args...)
+	Default().Errorf(message, args...)
 }
 
 func ErrorWithFields(fields Fields, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Error(args...)
+	Default().WithFields(logrus.Fields(fields)).Error(args...)
 }
 
 func ErrorWithFieldsf(fields Fields, message string, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Errorf(message, args...)
+	Default().WithFields(logrus.Fields(fields)).Errorf(message, args...)
 }
 
 func NewError(args ...interface{}) error {
@@ -168,72 +213,85 @@ func NewErrorWithFieldsf(fields Fields, message string, args ...interface{}) err
 }
 
 func Warn(args ...interface{}) {
-	logger.Warn(args...)
+	Default().Warn(args...)
 }
 
 func Warnf(message string, args ...interface{}) {
-	logger.Warnf(message, args...)
+	Default().Warnf(message, args...)
 }
 
 func WarnWithFields(fields Fields, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Warn(args...)
+	Default().WithFields(logrus.Fields(fields)).Warn(args...)
 }
 
 func WarnWithFieldsf(fields Fields, message string, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Warnf(message, args...)
+	Default().WithFields(logrus.Fields(fields)).Warnf(message, args...)
 }
 
 func Fatal(args ...interface{}) {
-	logger.Fatal(args...)
+	Default().Fatal(args...)
 }
 
 func Fatalf(message string, args ...interface{}) {
-	logger.Fatalf(message, args...)
+	Default().Fatalf(message, args...)
 }
 
 func FatalWithFields(fields Fields, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Fatal(args...)
+	Default().WithFields(logrus.Fields(fields)).Fatal(args...)
 }
 
 func FatalWithFieldsf(fields Fields, message string, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Fatalf(message, args...)
+	Default().WithFields(logrus.Fields(fields)).Fatalf(message, args...)
 }
 
 func Panic(args ...interface{}) {
-	logger.Panic(args...)
+	Default().Panic(args...)
 }
 
 func Panicf(message string, args ...interface{}) {
-	logger.Panicf(message, args...)
+	Default().Panicf(message, args...)
 }
 
 func PanicWithFields(fields Fields, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Panic(args...)
+	Default().WithFields(logrus.Fields(fields)).Panic(args...)
 }
 
 func PanicWithFieldsf(fields Fields, message string, args ...interface{}) {
-	logger.WithFields(logrus.Fields(fields)).Panicf(message, args...)
+	Default().WithFields(logrus.Fields(fields)).Panicf(message, args...)
 }
 
 // ServerLogger is a middleware that logs the start and end of each request, along
 // with some useful data about what was requested, what the response status was,
-// and how long it took to return.
-func ServerLogger() func(next http.Handler) http.Handler {
+// and how long it took to return. The final entry includes the fields the handler adds
+// to the request context in place; see FromRequest.
+func ServerLogger(opts ...HTTPOption) func(next http.Handler) http.Handler {
+	o := newHTTPOptions(opts)
+
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
+			if o.skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r = o.prepareRequest(r, func(ctx context.Context) FieldLogger {
+				return NewFieldLogger(Default()).WithContext(ctx)
+			})
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			capture := o.capture.startCapture(r, ww)
 
 			t1 := time.Now()
 			defer func() {
-				logger.WithFields(logrus.Fields{
-					"proto":     r.Proto,
-					"path":      r.URL.Path,
-					"duration":  time.Since(t1),
-					"status":    ww.Status(),
-					"size":      ww.BytesWritten(),
-					"ip":        r.RemoteAddr,
-					"requestID": middleware.GetReqID(r.Context()),
-				}).Info("Request Served")
+				duration := time.Since(t1)
+				if !o.sample(r, ww.Status(), duration) {
+					return
+				}
+				extra := append([]httpField{{"requestID", middleware.GetReqID(r.Context())}}, capture.fields()...)
+				fields := o.requestFields(r, ww, duration, extra...)
+				Default().WithFields(httpLogrusFields(fields)).Log(
+					o.requestLevel(ww.Status(), duration),
+					o.requestMessage("Request Served"),
+				)
 			}()
 
 			next.ServeHTTP(ww, r)
@@ -0,0 +1,96 @@
+package log
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Logger", func() {
+	var previous *logrus.Logger
+
+	BeforeEach(func() {
+		previous = Default()
+	})
+
+	AfterEach(func() {
+		SetDefault(previous)
+	})
+
+	Describe("NewLogger", func() {
+		It("should not replace the default logger", func() {
+			l := NewLogger(WithJSONFormat())
+
+			g.Expect(l).ToNot(g.BeIdenticalTo(previous))
+			g.Expect(Default()).To(g.BeIdenticalTo(previous))
+		})
+
+		It("should apply the given options", func() {
+			buf := bytes.Buffer{}
+			l := NewLogger(
+				WithJSONFormat(),
+				WithLevel(logrus.WarnLevel),
+				WithOutput(&buf),
+				WithTimeFormat("2006"),
+			)
+
+			l.Info("hidden")
+			l.Warn("shown")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(len(b.Parsed)).To(g.Equal(1))
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("shown"))
+			g.Expect(b.Parsed[0]["time"]).To(g.HaveLen(4))
+		})
+
+		It("should install hooks", func() {
+			hook := &countingHook{}
+			l := NewLogger(WithHooks(hook), WithOutput(&bytes.Buffer{}))
+
+			l.Info("hooked")
+
+			g.Expect(hook.count).To(g.Equal(1))
+		})
+	})
+
+	Describe("SetDefault", func() {
+		It("should route package level functions to the new default", func() {
+			buf := bytes.Buffer{}
+			SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+
+			Info("through default")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.LogInLogs("msg", "through default")).To(g.BeTrue())
+		})
+
+		It("should ignore nil", func() {
+			SetDefault(nil)
+			g.Expect(Default()).To(g.BeIdenticalTo(previous))
+		})
+	})
+
+	Describe("NewPrefixedLogger", func() {
+		It("should not replace the default logger when no instance is given", func() {
+			NewPrefixedLogger("test", nil)
+			g.Expect(Default()).To(g.BeIdenticalTo(previous))
+		})
+	})
+})
+
+type countingHook struct {
+	count int
+}
+
+func (h *countingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *countingHook) Fire(*logrus.Entry) error {
+	h.count++
+	return nil
+}
@@ -0,0 +1,115 @@
+package log
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Option configures a logger built by NewLogger.
+type Option func(*options)
+
+type options struct {
+	jsonFormatted bool
+	formatter     logrus.Formatter
+	level         Level
+	levelVar      *LevelVar
+	out           io.Writer
+	hooks         []logrus.Hook
+	reportCaller  bool
+	timeFormat    string
+	slogHandler   slog.Handler
+}
+
+func defaultOptions() *options {
+	return &options{
+		level: logrus.InfoLevel,
+	}
+}
+
+// WithJSONFormat makes the logger emit JSON instead of colored text.
+func WithJSONFormat() Option {
+	return func(o *options) {
+		o.jsonFormatted = true
+	}
+}
+
+// WithFormatter sets a custom formatter. It takes precedence over WithJSONFormat
+// and WithTimeFormat.
+func WithFormatter(formatter logrus.Formatter) Option {
+	return func(o *options) {
+		o.formatter = formatter
+	}
+}
+
+// WithLevel sets the minimum level the logger will emit.
+func WithLevel(level Level) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// WithLevelVar makes the logger follow the runtime adjustable level held by v. It takes
+// precedence over WithLevel.
+func WithLevelVar(v *LevelVar) Option {
+	return func(o *options) {
+		o.levelVar = v
+	}
+}
+
+// WithOutput sets the writer log entries are written to. Defaults to stderr.
+func WithOutput(out io.Writer) Option {
+	return func(o *options) {
+		o.out = out
+	}
+}
+
+// WithHooks adds hooks to the logger.
+func WithHooks(hooks ...logrus.Hook) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, hooks...)
+	}
+}
+
+// WithReportCaller adds the calling function and file to every entry.
+func WithReportCaller(enabled bool) Option {
+	return func(o *options) {
+		o.reportCaller = enabled
+	}
+}
+
+// WithTimeFormat sets the timestamp layout used by the built-in formatters.
+func WithTimeFormat(layout string) Option {
+	return func(o *options) {
+		o.timeFormat = layout
+	}
+}
+
+// WithSLogHandler forwards every entry to h instead of writing it, so the package level
+// functions and ServerLogger can log through slog. The output and formatter options are
+// ignored.
+func WithSLogHandler(h slog.Handler) Option {
+	return func(o *options) {
+		o.slogHandler = h
+	}
+}
+
+func (o *options) buildFormatter() logrus.Formatter {
+	if o.formatter != nil {
+		return o.formatter
+	}
+
+	if o.jsonFormatted {
+		return &logrus.JSONFormatter{
+			TimestampFormat: o.timeFormat,
+		}
+	}
+
+	return &logrus.TextFormatter{
+		ForceColors:            true,
+		DisableLevelTruncation: true,
+		FullTimestamp:          o.timeFormat != "",
+		TimestampFormat:        o.timeFormat,
+	}
+}
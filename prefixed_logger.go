@@ -1,141 +1,499 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/neighborly/go-errors"
 	"github.com/sirupsen/logrus"
 )
 
+// ComponentFieldKey is the field PrefixedLogger emits its prefix under when its style
+// includes PrefixAsField.
+const ComponentFieldKey = "component"
+
+// PrefixStyle controls how a PrefixedLogger emits its prefix.
+type PrefixStyle int
+
+const (
+	// PrefixInMessage prepends the prefix to the message, as in "task-consumer: message".
+	PrefixInMessage PrefixStyle = iota
+	// PrefixAsField emits the prefix only as the ComponentFieldKey field.
+	PrefixAsField
+	// PrefixInMessageAndField does both.
+	PrefixInMessageAndField
+)
+
+// PrefixedLogger prefixes every message with Prefix. It wraps any Logger; a wrapped
+// FieldLogger, *logrus.Logger or *logrus.Entry also receives the structured fields and
+// context, while fields given to a plain Logger are appended to the message.
 type PrefixedLogger struct {
-	Prefix         string
+	Prefix string
+	// Instance is the wrapped logger. When nil, LoggerInstance is used.
+	Instance Logger
+	// LoggerInstance is the logrus logger backing Instance, if any.
 	LoggerInstance *logrus.Logger
+	// Levels holds per prefix levels overriding the level of the wrapped logger. Defaults
+	// to ComponentLevels(); nil disables per prefix levels.
+	Levels *LevelRegistry
+	// Style controls whether the prefix is emitted in the message, as a field, or both.
+	Style PrefixStyle
+
+	fields Fields
+	ctx    context.Context
 }
 
+var _ FieldLogger = (*PrefixedLogger)(nil)
+
 func NewPrefixedLogger(prefix string, instance Logger) PrefixedLogger {
 	var _logger *logrus.Logger
-	if instance == nil {
-		_logger = New(true, "info")
-	} else if logrusLogger, ok := instance.(*logrus.Logger); ok {
-		_logger = logrusLogger
+	switch i := instance.(type) {
+	case nil:
+		_logger = NewLogger(WithJSONFormat())
+		instance = _logger
+	case *logrus.Logger:
+		_logger = i
+	case *logrus.Entry:
+		_logger = i.Logger
+	case *entryLogger:
+		_logger = i.entry.Logger
 	}
 
 	return PrefixedLogger{
 		Prefix:         prefix,
+		Instance:       instance,
 		LoggerInstance: _logger,
+		Levels:         ComponentLevels(),
+	}
+}
+
+// Sub returns a logger for a nested component whose prefix is "parent.name". It shares
+// the wrapped logger, levels, style, fields and context of l.
+func (l *PrefixedLogger) Sub(name string) PrefixedLogger {
+	c := *l
+	if l.Prefix != "" {
+		c.Prefix = l.Prefix + "." + name
+	} else {
+		c.Prefix = name
 	}
+	return c
 }
 
 func (l *PrefixedLogger) prefixArgs(args []interface{}) []interface{} {
+	if l.Style == PrefixAsField {
+		return args
+	}
 	return append([]interface{}{l.Prefix + ": "}, args...)
 }
 
 func (l *PrefixedLogger) prefixMsg(message string) string {
+	if l.Style == PrefixAsField {
+		return message
+	}
 	return fmt.Sprintf("%s: %s", l.Prefix, message)
 }
 
+// entryFields returns the fields of l, including the component field when enabled,
+// merged with fields.
+func (l *PrefixedLogger) entryFields(fields Fields) Fields {
+	if l.Style == PrefixInMessage {
+		return mergeFields(l.fields, fields)
+	}
+	return mergeFields(mergeFields(Fields{ComponentFieldKey: l.Prefix}, l.fields), fields)
+}
+
+func (l *PrefixedLogger) wrapped() Logger {
+	if l.Instance != nil {
+		return l.Instance
+	}
+	return l.LoggerInstance
+}
+
+// baseEntry returns the logrus entry backing the wrapped logger, or nil if it is not
+// backed by logrus.
+func (l *PrefixedLogger) baseEntry() *logrus.Entry {
+	switch i := l.wrapped().(type) {
+	case *logrus.Logger:
+		if i != nil {
+			return logrus.NewEntry(i)
+		}
+	case *logrus.Entry:
+		return i
+	case *entryLogger:
+		return i.entry
+	}
+	return nil
+}
+
+func (l *PrefixedLogger) componentLevel() (Level, bool) {
+	if l.Levels == nil {
+		return 0, false
+	}
+	return l.Levels.Lookup(l.Prefix)
+}
+
+// entry returns the logrus entry to write at level with, or false if the level of the
+// prefix, or of the wrapped logger when the prefix has none, suppresses it.
+func (l *PrefixedLogger) entry(base *logrus.Entry, level Level, fields Fields) (*logrus.Entry, bool) {
+	if componentLevel, ok := l.componentLevel(); ok {
+		if componentLevel < level {
+			return nil, false
+		}
+	} else if !base.Logger.IsLevelEnabled(level) {
+		return nil, false
+	}
+
+	entry := base
+	if all := l.entryFields(fields); len(all) > 0 {
+		entry = entry.WithFields(logrus.Fields(all))
+	}
+	if l.ctx != nil {
+		entry = entry.WithContext(l.ctx)
+	}
+	return entry, true
+}
+
+// sharedOutput is the output of loggers built with NewLogger. It serializes writes, so
+// that entries PrefixedLogger writes past the level of such a logger can go through a
+// second logger sharing the output.
+type sharedOutput struct {
+	mu      sync.Mutex
+	out     io.Writer
+	once    sync.Once
+	verbose *logrus.Logger
+}
+
+func (o *sharedOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.out.Write(p)
+}
+
+// verboseLogger returns the logger writing every level to the output of base, built on
+// first use with the formatter and a copy of the hooks of base. It returns nil when the
+// output of base is not a sharedOutput.
+func verboseLogger(base *logrus.Logger) *logrus.Logger {
+	out, ok := base.Out.(*sharedOutput)
+	if !ok {
+		return nil
+	}
+	out.once.Do(func() {
+		hooks := make(logrus.LevelHooks, len(base.Hooks))
+		for level, levelHooks := range base.Hooks {
+			hooks[level] = append([]logrus.Hook(nil), levelHooks...)
+		}
+		out.verbose = &logrus.Logger{
+			Out:          out,
+			Formatter:    base.Formatter,
+			Hooks:        hooks,
+			Level:        logrus.TraceLevel,
+			ExitFunc:     base.ExitFunc,
+			ReportCaller: base.ReportCaller,
+		}
+	})
+	return out.verbose
+}
+
+// writeEntry logs message at level. When the prefix level is more verbose than the
+// logrus logger, the entry is logged through its verbose logger instead, or dropped if it
+// has none.
+func writeEntry(entry *logrus.Entry, level Level, message string) {
+	if !entry.Logger.IsLevelEnabled(level) {
+		verbose := verboseLogger(entry.Logger)
+		if verbose == nil {
+			return
+		}
+		entry = &logrus.Entry{Logger: verbose, Data: entry.Data, Context: entry.Context}
+	}
+	entry.Log(level, message)
+}
+
+// target returns the wrapped logger carrying the prefixed logger's fields and context,
+// and the fields it could not attach. It returns false if the level of the prefix
+// suppresses the entry.
+func (l *PrefixedLogger) target(level Level, fields Fields) (Logger, Fields, bool) {
+	if componentLevel, ok := l.componentLevel(); ok && componentLevel < level && level > logrus.FatalLevel {
+		return nil, nil, false
+	}
+
+	target := l.wrapped()
+	fields = l.entryFields(fields)
+	if fl, ok := target.(FieldLogger); ok {
+		if len(fields) > 0 {
+			fl = fl.WithFields(fields)
+		}
+		if l.ctx != nil {
+			fl = fl.WithContext(l.ctx)
+		}
+		return fl, nil, true
+	}
+	return target, fields, true
+}
+
+func (l *PrefixedLogger) log(level Level, fields Fields, args []interface{}) {
+	base := l.baseEntry()
+	if base == nil {
+		if target, extra, ok := l.target(level, fields); ok {
+			if len(extra) > 0 {
+				args = append(args, " ", formatFields(extra))
+			}
+			logAt(target, level, l.prefixArgs(args))
+		}
+		return
+	}
+
+	if entry, ok := l.entry(base, level, fields); ok {
+		writeEntry(entry, level, fmt.Sprint(l.prefixArgs(args)...))
+	}
+	if level == logrus.FatalLevel {
+		base.Logger.Exit(1)
+	}
+}
+
+func (l *PrefixedLogger) logf(level Level, fields Fields, message string, args []interface{}) {
+	base := l.baseEntry()
+	if base == nil {
+		if target, extra, ok := l.target(level, fields); ok {
+			message = l.prefixMsg(message)
+			if len(extra) > 0 {
+				message += " %s"
+				args = append(args, formatFields(extra))
+			}
+			logfAt(target, level, message, args)
+		}
+		return
+	}
+
+	if entry, ok := l.entry(base, level, fields); ok {
+		writeEntry(entry, level, fmt.Sprintf(l.prefixMsg(message), args...))
+	}
+	if level == logrus.FatalLevel {
+		base.Logger.Exit(1)
+	}
+}
+
+func logAt(l Logger, level Level, args []interface{}) {
+	switch level {
+	case logrus.PanicLevel:
+		l.Panic(args...)
+	case logrus.FatalLevel:
+		l.Fatal(args...)
+	case logrus.ErrorLevel:
+		l.Error(args...)
+	case logrus.WarnLevel:
+		l.Warn(args...)
+	case logrus.InfoLevel:
+		l.Info(args...)
+	default:
+		l.Debug(args...)
+	}
+}
+
+func logfAt(l Logger, level Level, message string, args []interface{}) {
+	switch level {
+	case logrus.PanicLevel:
+		l.Panicf(message, args...)
+	case logrus.FatalLevel:
+		l.Fatalf(message, args...)
+	case logrus.ErrorLevel:
+		l.Errorf(message, args...)
+	case logrus.WarnLevel:
+		l.Warnf(message, args...)
+	case logrus.InfoLevel:
+		l.Infof(message, args...)
+	default:
+		l.Debugf(message, args...)
+	}
+}
+
 // implement logger interface
 func (l *PrefixedLogger) Info(args ...interface{}) {
-	l.LoggerInstance.Info(l.prefixArgs(args)...)
+	l.log(logrus.InfoLevel, nil, args)
 }
 
 func (l *PrefixedLogger) Infof(message string, args ...interface{}) {
-	l.LoggerInstance.Infof(l.prefixMsg(message), args...)
+	l.logf(logrus.InfoLevel, nil, message, args)
 }
 
 func (l *PrefixedLogger) Debug(args ...interface{}) {
-	l.LoggerInstance.Debug(l.prefixArgs(args)...)
+	l.log(logrus.DebugLevel, nil, args)
 }
 
 func (l *PrefixedLogger) Debugf(message string, args ...interface{}) {
-	l.LoggerInstance.Debugf(l.prefixMsg(message), args...)
+	l.logf(logrus.DebugLevel, nil, message, args)
 }
 
 func (l *PrefixedLogger) Error(args ...interface{}) {
-	l.LoggerInstance.Error(l.prefixArgs(args)...)
+	l.log(logrus.ErrorLevel, nil, args)
 }
 
 func (l *PrefixedLogger) Errorf(message string, args ...interface{}) {
-	l.LoggerInstance.Errorf(l.prefixMsg(message), args...)
+	l.logf(logrus.ErrorLevel, nil, message, args)
 }
 
 func (l *PrefixedLogger) Warn(args ...interface{}) {
-	l.LoggerInstance.Warn(l.prefixArgs(args)...)
+	l.log(logrus.WarnLevel, nil, args)
 }
 
 func (l *PrefixedLogger) Warnf(message string, args ...interface{}) {
-	l.LoggerInstance.Warnf(l.prefixMsg(message), args...)
+	l.logf(logrus.WarnLevel, nil, message, args)
 }
 
 func (l *PrefixedLogger) Fatal(args ...interface{}) {
-	l.LoggerInstance.Fatal(l.prefixArgs(args)...)
+	l.log(logrus.FatalLevel, nil, args)
 }
 
 func (l *PrefixedLogger) Fatalf(message string, args ...interface{}) {
-	l.LoggerInstance.Fatalf(l.prefixMsg(message), args...)
+	l.logf(logrus.FatalLevel, nil, message, args)
 }
 
 func (l *PrefixedLogger) Panic(args ...interface{}) {
-	l.LoggerInstance.Panic(l.prefixArgs(args)...)
+	l.log(logrus.PanicLevel, nil, args)
 }
 
 func (l *PrefixedLogger) Panicf(message string, args ...interface{}) {
-	l.LoggerInstance.Panicf(l.prefixMsg(message), args...)
+	l.logf(logrus.PanicLevel, nil, message, args)
 }
 
 func (l *PrefixedLogger) Writer() *io.PipeWriter {
-	return l.LoggerInstance.Writer()
+	return l.wrapped().Writer()
 }
 
 // fields
 
+// WithField returns a copy of the logger that adds the given field to every entry.
+func (l *PrefixedLogger) WithField(key string, value interface{}) FieldLogger {
+	return l.WithFields(Fields{key: value})
+}
+
+// WithFields returns a copy of the logger that adds the given fields to every entry.
+func (l *PrefixedLogger) WithFields(fields Fields) FieldLogger {
+	c := *l
+	c.fields = mergeFields(l.fields, fields)
+	return &c
+}
+
+// WithError returns a copy of the logger that adds err to every entry.
+func (l *PrefixedLogger) WithError(err error) FieldLogger {
+	return l.WithField(logrus.ErrorKey, err)
+}
+
+// WithContext returns a copy of the logger that attaches ctx to every entry.
+func (l *PrefixedLogger) WithContext(ctx context.Context) FieldLogger {
+	c := *l
+	c.ctx = ctx
+	return &c
+}
+
 func (l *PrefixedLogger) InfoWithFields(fields Fields, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Info(l.prefixArgs(args)...)
+	l.log(logrus.InfoLevel, fields, args)
 }
 
 func (l *PrefixedLogger) InfoWithFieldsf(fields Fields, message string, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Infof(l.prefixMsg(message), args...)
+	l.logf(logrus.InfoLevel, fields, message, args)
 }
 
 func (l *PrefixedLogger) DebugWithFields(fields Fields, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Debug(l.prefixArgs(args)...)
+	l.log(logrus.DebugLevel, fields, args)
 }
 
 func (l *PrefixedLogger) DebugWithFieldsf(fields Fields, message string, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Debugf(l.prefixMsg(message), args...)
+	l.logf(logrus.DebugLevel, fields, message, args)
 }
 
 func (l *PrefixedLogger) ErrorWithFields(fields Fields, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Error(l.prefixArgs(args)...)
+	l.log(logrus.ErrorLevel, fields, args)
 }
 
 func (l *PrefixedLogger) ErrorWithFieldsf(fields Fields, message string, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Errorf(l.prefixMsg(message), args...)
+	l.logf(logrus.ErrorLevel, fields, message, args)
 }
 
 func (l *PrefixedLogger) WarnWithFields(fields Fields, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Warn(l.prefixArgs(args)...)
+	l.log(logrus.WarnLevel, fields, args)
 }
 
 func (l *PrefixedLogger) WarnWithFieldsf(fields Fields, message string, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Warnf(l.prefixMsg(message), args...)
+	l.logf(logrus.WarnLevel, fields, message, args)
 }
 
 func (l *PrefixedLogger) FatalWithFields(fields Fields, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Fatal(l.prefixArgs(args)...)
+	l.log(logrus.FatalLevel, fields, args)
 }
 
 func (l *PrefixedLogger) FatalWithFieldsf(fields Fields, message string, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Fatalf(l.prefixMsg(message), args...)
+	l.logf(logrus.FatalLevel, fields, message, args)
 }
 
 func (l *PrefixedLogger) PanicWithFields(fields Fields, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Panic(l.prefixArgs(args)...)
+	l.log(logrus.PanicLevel, fields, args)
 }
 
 func (l *PrefixedLogger) PanicWithFieldsf(fields Fields, message string, args ...interface{}) {
-	l.LoggerInstance.WithFields(logrus.Fields(fields)).Panicf(l.prefixMsg(message), args...)
+	l.logf(logrus.PanicLevel, fields, message, args)
+}
+
+// context
+
+// withContextFields returns a copy of the logger carrying the logging fields of ctx, the
+// chi request ID and ctx itself. Fields set on the logger take precedence. The fields are
+// merged here rather than left to ContextHook, since the wrapped logger may not have it
+// installed.
+func (l *PrefixedLogger) withContextFields(ctx context.Context) *PrefixedLogger {
+	c := *l
+	c.fields = mergeFields(contextFields(ctx), l.fields)
+	c.ctx = ctx
+	return &c
+}
+
+func (l *PrefixedLogger) InfoCtx(ctx context.Context, args ...interface{}) {
+	l.withContextFields(ctx).log(logrus.InfoLevel, nil, args)
+}
+
+func (l *PrefixedLogger) InfoCtxf(ctx context.Context, message string, args ...interface{}) {
+	l.withContextFields(ctx).logf(logrus.InfoLevel, nil, message, args)
+}
+
+func (l *PrefixedLogger) DebugCtx(ctx context.Context, args ...interface{}) {
+	l.withContextFields(ctx).log(logrus.DebugLevel, nil, args)
+}
+
+func (l *PrefixedLogger) DebugCtxf(ctx context.Context, message string, args ...interface{}) {
+	l.withContextFields(ctx).logf(logrus.DebugLevel, nil, message, args)
+}
+
+func (l *PrefixedLogger) ErrorCtx(ctx context.Context, args ...interface{}) {
+	l.withContextFields(ctx).log(logrus.ErrorLevel, nil, args)
+}
+
+func (l *PrefixedLogger) ErrorCtxf(ctx context.Context, message string, args ...interface{}) {
+	l.withContextFields(ctx).logf(logrus.ErrorLevel, nil, message, args)
+}
+
+func (l *PrefixedLogger) WarnCtx(ctx context.Context, args ...interface{}) {
+	l.withContextFields(ctx).log(logrus.WarnLevel, nil, args)
+}
+
+func (l *PrefixedLogger) WarnCtxf(ctx context.Context, message string, args ...interface{}) {
+	l.withContextFields(ctx).logf(logrus.WarnLevel, nil, message, args)
+}
+
+func (l *PrefixedLogger) FatalCtx(ctx context.Context, args ...interface{}) {
+	l.withContextFields(ctx).log(logrus.FatalLevel, nil, args)
+}
+
+func (l *PrefixedLogger) FatalCtxf(ctx context.Context, message string, args ...interface{}) {
+	l.withContextFields(ctx).logf(logrus.FatalLevel, nil, message, args)
+}
+
+func (l *PrefixedLogger) PanicCtx(ctx context.Context, args ...interface{}) {
+	l.withContextFields(ctx).log(logrus.PanicLevel, nil, args)
+}
+
+func (l *PrefixedLogger) PanicCtxf(ctx context.Context, message string, args ...interface{}) {
+	l.withContextFields(ctx).logf(logrus.PanicLevel, nil, message, args)
 }
 
 // error wrapping
@@ -2,9 +2,13 @@ package log
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"sync"
 
 	. "github.com/onsi/ginkgo"
 	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
 )
 
 var _ = Describe("PrefixedLogger", func() {
@@ -49,4 +53,298 @@ var _ = Describe("PrefixedLogger", func() {
 		g.Expect(b.Parsed[0]["level"]).To(g.Equal("info"))
 		g.Expect(b.Parsed[0]["msg"]).To(g.Equal("test: goldfish"))
 	})
+
+	Describe("component levels", func() {
+		var (
+			buf    bytes.Buffer
+			logger *logrus.Logger
+			levels *LevelRegistry
+		)
+
+		BeforeEach(func() {
+			buf = bytes.Buffer{}
+			logger = NewLogger(WithJSONFormat(), WithOutput(&buf))
+			levels = NewLevelRegistry()
+		})
+
+		newPrefixed := func(prefix string) PrefixedLogger {
+			pl := NewPrefixedLogger(prefix, logger)
+			pl.Levels = levels
+			return pl
+		}
+
+		It("should use the component level configured for its prefix", func() {
+			levels.Set("task-consumer.*", logrus.DebugLevel)
+			noisy := newPrefixed("task-consumer.sqs")
+			quiet := newPrefixed("api")
+
+			noisy.DebugWithFields(Fields{"queue": "jobs"}, "polling")
+			quiet.Debug("hidden")
+			logger.Debug("hidden")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(len(b.Parsed)).To(g.Equal(1))
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("task-consumer.sqs: polling"))
+			g.Expect(b.Parsed[0]["level"]).To(g.Equal("debug"))
+			g.Expect(b.Parsed[0]["queue"]).To(g.Equal("jobs"))
+		})
+
+		It("should serialize entries written past the logger level with the logger's own", func() {
+			levels.Set("worker", logrus.DebugLevel)
+			pl := newPrefixed("worker")
+
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					logger.Infof("served %d", i)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					pl.Debugf("tick %d", i)
+				}
+			}()
+			wg.Wait()
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			counts := map[interface{}]int{}
+			for _, line := range b.Parsed {
+				counts[line["level"]]++
+			}
+			g.Expect(counts).To(g.Equal(map[interface{}]int{"info": 50, "debug": 50}))
+		})
+
+		It("should drop entries past the level of a logger with a replaced output", func() {
+			levels.Set("worker", logrus.DebugLevel)
+			var out bytes.Buffer
+			logger.SetOutput(&out)
+
+			pl := newPrefixed("worker")
+			pl.Debug("hidden")
+			pl.Info("shown")
+
+			g.Expect(out.String()).ToNot(g.ContainSubstring("hidden"))
+			g.Expect(out.String()).To(g.ContainSubstring("shown"))
+		})
+
+		It("should suppress entries below the component level", func() {
+			levels.Set("api", logrus.ErrorLevel)
+			pl := newPrefixed("api")
+
+			pl.Warnf("hidden %d", 1)
+			pl.Errorf("shown %d", 2)
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(len(b.Parsed)).To(g.Equal(1))
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("api: shown 2"))
+		})
+
+		It("should use the logger level when no pattern matches", func() {
+			pl := newPrefixed("api")
+
+			pl.Debug("hidden")
+			pl.Info("shown")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(len(b.Parsed)).To(g.Equal(1))
+		})
+
+		It("should still exit on fatal when suppressed", func() {
+			exited := 0
+			logger.ExitFunc = func(int) { exited++ }
+			levels.Set("api", logrus.PanicLevel)
+			pl := newPrefixed("api")
+
+			pl.Fatal("hidden")
+
+			g.Expect(exited).To(g.Equal(1))
+			g.Expect(buf.Len()).To(g.Equal(0))
+		})
+	})
+
+	Describe("prefix style", func() {
+		var (
+			buf    bytes.Buffer
+			logger *logrus.Logger
+		)
+
+		BeforeEach(func() {
+			buf = bytes.Buffer{}
+			logger = NewLogger(WithJSONFormat(), WithOutput(&buf))
+		})
+
+		It("should emit the prefix only as a field", func() {
+			pl := NewPrefixedLogger("task-consumer", logger)
+			pl.Style = PrefixAsField
+
+			pl.Infof("processed %d", 3)
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("processed 3"))
+			g.Expect(b.Parsed[0][ComponentFieldKey]).To(g.Equal("task-consumer"))
+		})
+
+		It("should emit the prefix in the message and as a field", func() {
+			pl := NewPrefixedLogger("task-consumer", logger)
+			pl.Style = PrefixInMessageAndField
+
+			pl.WarnWithFields(Fields{"id": "1"}, "slow")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("task-consumer: slow"))
+			g.Expect(b.Parsed[0][ComponentFieldKey]).To(g.Equal("task-consumer"))
+			g.Expect(b.Parsed[0]["id"]).To(g.Equal("1"))
+		})
+
+		It("should not emit the field by default", func() {
+			pl := NewPrefixedLogger("task-consumer", logger)
+
+			pl.Info("plain")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed[0]).ToNot(g.HaveKey(ComponentFieldKey))
+		})
+
+		It("should nest sub components", func() {
+			pl := NewPrefixedLogger("task-consumer", logger)
+			pl.Style = PrefixInMessageAndField
+			sub := pl.WithField("queue", "jobs").(*PrefixedLogger).Sub("sqs")
+			subsub := sub.Sub("poller")
+
+			subsub.Info("polling")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("task-consumer.sqs.poller: polling"))
+			g.Expect(b.Parsed[0][ComponentFieldKey]).To(g.Equal("task-consumer.sqs.poller"))
+			g.Expect(b.Parsed[0]["queue"]).To(g.Equal("jobs"))
+			g.Expect(pl.Prefix).To(g.Equal("task-consumer"))
+		})
+
+		It("should match sub components against hierarchical levels", func() {
+			levels := NewLevelRegistry()
+			levels.Set("task-consumer.*", logrus.DebugLevel)
+			pl := NewPrefixedLogger("task-consumer", logger)
+			pl.Levels = levels
+			sub := pl.Sub("sqs")
+
+			sub.Debug("visible")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed).To(g.HaveLen(1))
+		})
+	})
+
+	Describe("wrapped loggers", func() {
+		var (
+			buf    bytes.Buffer
+			logger *logrus.Logger
+		)
+
+		BeforeEach(func() {
+			buf = bytes.Buffer{}
+			logger = NewLogger(WithJSONFormat(), WithOutput(&buf))
+		})
+
+		It("should keep the fields of a logrus entry", func() {
+			pl := NewPrefixedLogger("handler", logger.WithField("requestID", "abc"))
+
+			pl.InfoWithFields(Fields{"user": "u1"}, "served")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed).To(g.HaveLen(1))
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("handler: served"))
+			g.Expect(b.Parsed[0]["requestID"]).To(g.Equal("abc"))
+			g.Expect(b.Parsed[0]["user"]).To(g.Equal("u1"))
+			g.Expect(pl.LoggerInstance).To(g.BeIdenticalTo(logger))
+		})
+
+		It("should wrap another FieldLogger", func() {
+			parent := NewPrefixedLogger("parent", NewFieldLogger(logger))
+			pl := NewPrefixedLogger("child", &parent)
+
+			pl.WithField("a", 1).Warnf("nested %s", "log")
+
+			b := ByteLogs{Log: &buf}
+			b.Parse(nil)
+			g.Expect(b.Parsed[0]["msg"]).To(g.Equal("parent: child: nested log"))
+			g.Expect(b.Parsed[0]["a"]).To(g.BeNumerically("==", 1))
+		})
+
+		It("should append fields to the message of a plain Logger", func() {
+			fake := &fakeLogger{}
+			pl := NewPrefixedLogger("fake", fake)
+
+			pl.ErrorWithFields(Fields{"b": 2, "a": 1}, "failed")
+			pl.InfoWithFieldsf(Fields{"a": "%d"}, "done %d", 5)
+
+			g.Expect(fake.lines).To(g.Equal([]string{
+				"error fake: failed a=1 b=2",
+				"info fake: done 5 a=%d",
+			}))
+			g.Expect(pl.LoggerInstance).To(g.BeNil())
+		})
+
+		It("should apply component levels to a plain Logger", func() {
+			fake := &fakeLogger{}
+			levels := NewLevelRegistry()
+			levels.Set("fake", logrus.WarnLevel)
+			pl := NewPrefixedLogger("fake", fake)
+			pl.Levels = levels
+
+			pl.Info("hidden")
+			pl.Warn("shown")
+
+			g.Expect(fake.lines).To(g.Equal([]string{"warn fake: shown"}))
+		})
+	})
 })
+
+type fakeLogger struct {
+	lines []string
+}
+
+func (f *fakeLogger) record(level string, msg string) {
+	f.lines = append(f.lines, level+" "+msg)
+}
+
+func (f *fakeLogger) Info(args ...interface{})  { f.record("info", fmt.Sprint(args...)) }
+func (f *fakeLogger) Debug(args ...interface{}) { f.record("debug", fmt.Sprint(args...)) }
+func (f *fakeLogger) Error(args ...interface{}) { f.record("error", fmt.Sprint(args...)) }
+func (f *fakeLogger) Warn(args ...interface{})  { f.record("warn", fmt.Sprint(args...)) }
+func (f *fakeLogger) Fatal(args ...interface{}) { f.record("fatal", fmt.Sprint(args...)) }
+func (f *fakeLogger) Panic(args ...interface{}) { f.record("panic", fmt.Sprint(args...)) }
+func (f *fakeLogger) Infof(m string, args ...interface{}) {
+	f.record("info", fmt.Sprintf(m, args...))
+}
+func (f *fakeLogger) Debugf(m string, args ...interface{}) {
+	f.record("debug", fmt.Sprintf(m, args...))
+}
+func (f *fakeLogger) Errorf(m string, args ...interface{}) {
+	f.record("error", fmt.Sprintf(m, args...))
+}
+func (f *fakeLogger) Warnf(m string, args ...interface{}) {
+	f.record("warn", fmt.Sprintf(m, args...))
+}
+func (f *fakeLogger) Fatalf(m string, args ...interface{}) {
+	f.record("fatal", fmt.Sprintf(m, args...))
+}
+func (f *fakeLogger) Panicf(m string, args ...interface{}) {
+	f.record("panic", fmt.Sprintf(m, args...))
+}
+func (f *fakeLogger) Writer() *io.PipeWriter {
+	_, w := io.Pipe()
+	return w
+}
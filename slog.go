@@ -2,38 +2,51 @@ package log
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/go-chi/chi/middleware"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // NewSLogChiMiddleware is used to log http request information. It takes
 // a pointer to an slog.Logger to use. If `l` is nil, it uses the
-// default logger
-func NewSLogChiMiddleware(l *slog.Logger) func(http.Handler) http.Handler {
+// default logger. The final entry includes the fields the handler adds to the request
+// context in place; see FromRequest.
+func NewSLogChiMiddleware(l *slog.Logger, opts ...HTTPOption) func(http.Handler) http.Handler {
 	if l == nil {
 		l = slog.Default()
 	}
 
+	o := newHTTPOptions(opts)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if o.skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r = o.prepareRequest(r, func(ctx context.Context) FieldLogger {
+				return NewSLogLogger(slog.New(NewSLogContextHandler(l.Handler(), nil))).WithContext(ctx)
+			})
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			capture := o.capture.startCapture(r, ww)
 
 			defer func(start time.Time) {
+				duration := time.Since(start)
+				if !o.sample(r, ww.Status(), duration) {
+					return
+				}
 				l.LogAttrs(
 					r.Context(),
-					slog.LevelInfo,
-					"HTTP Request Served",
-					slog.String("proto", r.Proto),
-					slog.String("path", r.URL.Path),
-					slog.Duration("duration", time.Since(start)),
-					slog.Int("status", ww.Status()),
-					slog.Int("size", ww.BytesWritten()),
-					slog.String("ip", r.RemoteAddr),
+					slogLevel(o.requestLevel(ww.Status(), duration)),
+					o.requestMessage("HTTP Request Served"),
+					httpSLogAttrs(o.requestFields(r, ww, duration, capture.fields()...))...,
 				)
 			}(time.Now())
 
@@ -64,8 +77,10 @@ func (DevScrubber) Scrub(vars map[string]any) map[string]any {
 	return vars
 }
 
-// NewSLogGraphQLResponseMiddleware is used to log GraphQL requests and responses.
-func NewSLogGraphQLResponseMiddleware(l *slog.Logger, s VariablesScrubber) graphql.ResponseMiddleware {
+// NewSLogGraphQLResponseMiddleware is used to log GraphQL requests and responses. With a
+// ResolverTracer registered, the entry also carries its resolver summary. Apollo gateway
+// schema polls are skipped unless WithGraphQLLogServiceDefinition is given.
+func NewSLogGraphQLResponseMiddleware(l *slog.Logger, s VariablesScrubber, opts ...GraphQLOption) graphql.ResponseMiddleware {
 	if l == nil {
 		l = slog.Default()
 	}
@@ -74,6 +89,8 @@ func NewSLogGraphQLResponseMiddleware(l *slog.Logger, s VariablesScrubber) graph
 		s = noopVariablesScrubber{}
 	}
 
+	o := newGraphQLOptions(opts)
+
 	return func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
 		var (
 			start = time.Now()
@@ -81,31 +98,108 @@ func NewSLogGraphQLResponseMiddleware(l *slog.Logger, s VariablesScrubber) graph
 			oc    = graphql.GetOperationContext(ctx)
 		)
 
-		if !strings.Contains(oc.RawQuery, "__ApolloGetServiceDefinition__") {
-			l.LogAttrs(
-				ctx,
-				slog.LevelInfo,
-				"GraphQL Request Served",
-				slog.Group(
-					"graphql",
-					slog.Group(
-						"req",
-						slog.String("query", oc.RawQuery),
-						slog.Any("variables", s.Scrub(oc.Variables)),
-					),
-					slog.Group(
-						"res",
-						slog.String("errors", res.Errors.Error()),
-					),
-					slog.Duration("duration", time.Since(start)),
-				),
-			)
+		if o.skip(oc) || !o.sample(oc, res) {
+			return res
+		}
+
+		var resAttrs []any
+		if res != nil && len(res.Errors) > 0 {
+			resAttrs = append(resAttrs, slog.Any("errors", graphQLErrors(res.Errors)))
+		}
+
+		attrs := []any{
+			slog.Group("req", o.requestAttrs(ctx, oc, s)...),
+			slog.Group("res", resAttrs...),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if trace := getResolverTrace(ctx); trace != nil {
+			attrs = append(attrs, trace.attr())
+		}
+		if rate := o.rate(oc, res); rate > 0 {
+			attrs = append(attrs, slog.Uint64("sampleRate", rate))
 		}
 
+		l.LogAttrs(ctx, slog.LevelInfo, "GraphQL Request Served", slog.Group("graphql", attrs...))
+
 		return res
 	}
 }
 
+// requestAttrs returns the attributes describing the GraphQL request.
+func (o *graphqlOptions) requestAttrs(ctx context.Context, oc *graphql.OperationContext, s VariablesScrubber) []any {
+	attrs := []any{
+		slog.String("operationName", operationName(oc)),
+		slog.String("operationType", operationType(oc)),
+	}
+	if !o.omitQuery {
+		query, truncated := truncateQuery(oc.RawQuery, o.maxQueryLength)
+		attrs = append(attrs, slog.String("query", query))
+		if truncated {
+			attrs = append(attrs, slog.Bool("queryTruncated", true))
+		}
+	}
+	if o.signature {
+		if signature := NormalizeQuery(oc.RawQuery); signature != "" {
+			attrs = append(attrs,
+				slog.String("signature", signature),
+				slog.String("signatureHash", hashSignature(signature)),
+			)
+		}
+	}
+	if apq := extension.GetApqStats(ctx); apq != nil {
+		attrs = append(attrs, slog.String("persistedQueryHash", apq.Hash))
+	}
+	return append(attrs, slog.Any("variables", s.Scrub(oc.Variables)))
+}
+
+// graphQLError is the structured form of a GraphQL error in the request log entry.
+type graphQLError struct {
+	Message   string              `json:"message"`
+	Path      string              `json:"path,omitempty"`
+	Locations []gqlerror.Location `json:"locations,omitempty"`
+	Code      string              `json:"code,omitempty"`
+}
+
+func graphQLErrors(errs gqlerror.List) []graphQLError {
+	out := make([]graphQLError, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		e := graphQLError{
+			Message:   err.Message,
+			Path:      err.Path.String(),
+			Locations: err.Locations,
+		}
+		if code, ok := err.Extensions["code"]; ok {
+			e.Code = fmt.Sprint(code)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// operationName returns the name of the executed operation, which is the operation
+// defined in the document when the request does not name one.
+func operationName(oc *graphql.OperationContext) string {
+	if oc.OperationName != "" {
+		return oc.OperationName
+	}
+	if oc.Operation != nil {
+		return oc.Operation.Name
+	}
+	return ""
+}
+
+// operationType returns query, mutation or subscription, or "" when the operation could
+// not be resolved.
+func operationType(oc *graphql.OperationContext) string {
+	if oc.Operation == nil {
+		return ""
+	}
+	return string(oc.Operation.Operation)
+}
+
 func SLogReplaceAttr(_ []string, a slog.Attr) slog.Attr {
 	switch a.Value.Kind() {
 	case slog.KindAny:
@@ -0,0 +1,409 @@
+package log
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slog levels matching the logrus levels slog has no name for.
+const (
+	SLogLevelTrace = slog.Level(-8)
+	SLogLevelFatal = slog.Level(12)
+	SLogLevelPanic = slog.Level(16)
+)
+
+func logrusLevel(level slog.Level) Level {
+	switch {
+	case level >= SLogLevelPanic:
+		return logrus.PanicLevel
+	case level >= SLogLevelFatal:
+		return logrus.FatalLevel
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	case level >= slog.LevelDebug:
+		return logrus.DebugLevel
+	}
+	return logrus.TraceLevel
+}
+
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case logrus.PanicLevel:
+		return SLogLevelPanic
+	case logrus.FatalLevel:
+		return SLogLevelFatal
+	case logrus.ErrorLevel:
+		return slog.LevelError
+	case logrus.WarnLevel:
+		return slog.LevelWarn
+	case logrus.InfoLevel:
+		return slog.LevelInfo
+	case logrus.DebugLevel:
+		return slog.LevelDebug
+	}
+	return SLogLevelTrace
+}
+
+// SLogLogrusHandler is a slog.Handler that writes records into a logrus logger, so slog
+// users such as NewSLogChiMiddleware share the output of the go-log logger. Attrs become
+// fields and groups become nested fields.
+type SLogLogrusHandler struct {
+	logger *logrus.Logger
+	fields logrus.Fields
+	groups []string
+}
+
+var _ slog.Handler = (*SLogLogrusHandler)(nil)
+
+// NewSLogLogrusHandler creates a slog.Handler writing into l. If l is nil, the default
+// logger is used.
+func NewSLogLogrusHandler(l *logrus.Logger) *SLogLogrusHandler {
+	if l == nil {
+		l = Default()
+	}
+	return &SLogLogrusHandler{logger: l, fields: logrus.Fields{}}
+}
+
+func (h *SLogLogrusHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(logrusLevel(level))
+}
+
+func (h *SLogLogrusHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := h.fields
+	if r.NumAttrs() > 0 {
+		attrs := make([]slog.Attr, 0, r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, a)
+			return true
+		})
+		fields = addSLogAttrs(h.fields, h.groups, attrs)
+	}
+
+	entry := h.logger.WithFields(fields).WithTime(r.Time)
+	if ctx != nil {
+		entry = entry.WithContext(ctx)
+	}
+
+	// logrus panics after writing a panic level entry; that is left to the caller.
+	defer func() {
+		if p := recover(); p != nil {
+			if _, ok := p.(*logrus.Entry); !ok {
+				panic(p)
+			}
+		}
+	}()
+	entry.Log(logrusLevel(r.Level), r.Message)
+	return nil
+}
+
+func (h *SLogLogrusHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &SLogLogrusHandler{
+		logger: h.logger,
+		fields: addSLogAttrs(h.fields, h.groups, attrs),
+		groups: h.groups,
+	}
+}
+
+func (h *SLogLogrusHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &SLogLogrusHandler{
+		logger: h.logger,
+		fields: h.fields,
+		groups: append(groups, name),
+	}
+}
+
+// addSLogAttrs returns a copy of fields with attrs added under the nested groups.
+func addSLogAttrs(fields logrus.Fields, groups []string, attrs []slog.Attr) logrus.Fields {
+	out := copyLogrusFields(fields)
+	m := out
+	for _, group := range groups {
+		child, _ := m[group].(logrus.Fields)
+		child = copyLogrusFields(child)
+		m[group] = child
+		m = child
+	}
+	for _, a := range attrs {
+		setSLogAttr(m, a)
+	}
+	return out
+}
+
+func setSLogAttr(m logrus.Fields, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		m[a.Key] = a.Value.Any()
+		return
+	}
+
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return
+	}
+	if a.Key == "" {
+		for _, ga := range attrs {
+			setSLogAttr(m, ga)
+		}
+		return
+	}
+
+	child, _ := m[a.Key].(logrus.Fields)
+	child = copyLogrusFields(child)
+	for _, ga := range attrs {
+		setSLogAttr(child, ga)
+	}
+	m[a.Key] = child
+}
+
+func copyLogrusFields(fields logrus.Fields) logrus.Fields {
+	out := make(logrus.Fields, len(fields))
+	for name, value := range fields {
+		out[name] = value
+	}
+	return out
+}
+
+// slogHook forwards logrus entries to a slog.Handler.
+type slogHook struct {
+	handler slog.Handler
+}
+
+func (slogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h slogHook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	level := slogLevel(entry.Level)
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	var pc uintptr
+	if entry.Caller != nil {
+		pc = entry.Caller.PC
+	}
+	r := slog.NewRecord(entry.Time, level, entry.Message, pc)
+	r.AddAttrs(fieldsToAttrs(Fields(entry.Data))...)
+	return h.handler.Handle(ctx, r)
+}
+
+// discardFormatter is used when entries are forwarded to slog instead of being written.
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+var _ FieldLogger = (*slogLogger)(nil)
+
+// NewSLogLogger returns a FieldLogger backed by l, so PrefixedLogger and other Logger users
+// can write to slog. Fatal logs at SLogLevelFatal and exits; Panic logs at SLogLevelPanic
+// and panics. If l is nil, slog.Default() is used.
+func NewSLogLogger(l *slog.Logger) FieldLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &slogLogger{logger: l}
+}
+
+func (l *slogLogger) context() context.Context {
+	if l.ctx == nil {
+		return context.Background()
+	}
+	return l.ctx
+}
+
+// log writes a record with the caller of the exported method as its source.
+func (l *slogLogger) log(level slog.Level, msg string, fields Fields) {
+	ctx := l.context()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if len(fields) > 0 {
+		r.AddAttrs(fieldsToAttrs(fields)...)
+	}
+	_ = l.logger.Handler().Handle(ctx, r)
+}
+
+func (l *slogLogger) with(args ...any) FieldLogger {
+	return &slogLogger{logger: l.logger.With(args...), ctx: l.ctx}
+}
+
+func (l *slogLogger) WithField(key string, value interface{}) FieldLogger {
+	return l.with(key, value)
+}
+
+func (l *slogLogger) WithFields(fields Fields) FieldLogger {
+	attrs := fieldsToAttrs(fields)
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return l.with(args...)
+}
+
+func (l *slogLogger) WithError(err error) FieldLogger {
+	return l.with(logrus.ErrorKey, err)
+}
+
+func (l *slogLogger) WithContext(ctx context.Context) FieldLogger {
+	return &slogLogger{logger: l.logger, ctx: ctx}
+}
+
+func (l *slogLogger) Info(args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprint(args...), nil)
+}
+
+func (l *slogLogger) Infof(message string, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(message, args...), nil)
+}
+
+func (l *slogLogger) Debug(args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprint(args...), nil)
+}
+
+func (l *slogLogger) Debugf(message string, args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprintf(message, args...), nil)
+}
+
+func (l *slogLogger) Error(args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprint(args...), nil)
+}
+
+func (l *slogLogger) Errorf(message string, args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(message, args...), nil)
+}
+
+func (l *slogLogger) Warn(args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprint(args...), nil)
+}
+
+func (l *slogLogger) Warnf(message string, args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(message, args...), nil)
+}
+
+func (l *slogLogger) Fatal(args ...interface{}) {
+	l.log(SLogLevelFatal, fmt.Sprint(args...), nil)
+	os.Exit(1)
+}
+
+func (l *slogLogger) Fatalf(message string, args ...interface{}) {
+	l.log(SLogLevelFatal, fmt.Sprintf(message, args...), nil)
+	os.Exit(1)
+}
+
+func (l *slogLogger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	l.log(SLogLevelPanic, msg, nil)
+	panic(msg)
+}
+
+func (l *slogLogger) Panicf(message string, args ...interface{}) {
+	msg := fmt.Sprintf(message, args...)
+	l.log(SLogLevelPanic, msg, nil)
+	panic(msg)
+}
+
+// Writer returns a pipe whose lines are logged at info level.
+func (l *slogLogger) Writer() *io.PipeWriter {
+	reader, writer := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			l.log(slog.LevelInfo, scanner.Text(), nil)
+		}
+		reader.Close()
+	}()
+	return writer
+}
+
+func (l *slogLogger) InfoWithFields(fields Fields, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprint(args...), fields)
+}
+
+func (l *slogLogger) InfoWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(message, args...), fields)
+}
+
+func (l *slogLogger) DebugWithFields(fields Fields, args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprint(args...), fields)
+}
+
+func (l *slogLogger) DebugWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprintf(message, args...), fields)
+}
+
+func (l *slogLogger) ErrorWithFields(fields Fields, args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprint(args...), fields)
+}
+
+func (l *slogLogger) ErrorWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(message, args...), fields)
+}
+
+func (l *slogLogger) WarnWithFields(fields Fields, args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprint(args...), fields)
+}
+
+func (l *slogLogger) WarnWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(message, args...), fields)
+}
+
+func (l *slogLogger) FatalWithFields(fields Fields, args ...interface{}) {
+	l.log(SLogLevelFatal, fmt.Sprint(args...), fields)
+	os.Exit(1)
+}
+
+func (l *slogLogger) FatalWithFieldsf(fields Fields, message string, args ...interface{}) {
+	l.log(SLogLevelFatal, fmt.Sprintf(message, args...), fields)
+	os.Exit(1)
+}
+
+func (l *slogLogger) PanicWithFields(fields Fields, args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	l.log(SLogLevelPanic, msg, fields)
+	panic(msg)
+}
+
+func (l *slogLogger) PanicWithFieldsf(fields Fields, message string, args ...interface{}) {
+	msg := fmt.Sprintf(message, args...)
+	l.log(SLogLevelPanic, msg, fields)
+	panic(msg)
+}
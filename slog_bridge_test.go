@@ -0,0 +1,155 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("slog bridge", func() {
+	var buf bytes.Buffer
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+	})
+
+	parseLines := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	Describe("SLogLogrusHandler", func() {
+		var logger *logrus.Logger
+
+		BeforeEach(func() {
+			logger = NewLogger(WithJSONFormat(), WithOutput(&buf), WithLevel(logrus.DebugLevel))
+		})
+
+		It("should write records into the logrus logger", func() {
+			l := slog.New(NewSLogLogrusHandler(logger))
+
+			l.Debug("debugging", "count", 1)
+			l.Warn("careful")
+			l.Log(context.Background(), SLogLevelTrace, "hidden")
+
+			logs := parseLines()
+			g.Expect(logs).To(g.HaveLen(2))
+			g.Expect(logs[0]["level"]).To(g.Equal("debug"))
+			g.Expect(logs[0]["msg"]).To(g.Equal("debugging"))
+			g.Expect(logs[0]["count"]).To(g.BeNumerically("==", 1))
+			g.Expect(logs[1]["level"]).To(g.Equal("warning"))
+		})
+
+		It("should map groups to nested fields", func() {
+			l := slog.New(NewSLogLogrusHandler(logger)).
+				With("service", "api").
+				WithGroup("http").
+				With("method", "GET")
+
+			l.Info("served", slog.Group("res", slog.Int("status", 200)), slog.Group("empty"))
+
+			logs := parseLines()
+			g.Expect(logs[0]["service"]).To(g.Equal("api"))
+			g.Expect(logs[0]["http"]).To(g.HaveKeyWithValue("method", "GET"))
+			g.Expect(logs[0]["http"]).To(g.HaveKeyWithValue("res", g.HaveKeyWithValue("status", g.BeNumerically("==", 200))))
+			g.Expect(logs[0]["http"]).ToNot(g.HaveKey("empty"))
+		})
+
+		It("should carry context fields through the logrus hooks", func() {
+			ctx := WithContext(context.Background(), MakeField("user", "u1"))
+
+			slog.New(NewSLogLogrusHandler(logger)).InfoContext(ctx, "with context")
+
+			g.Expect(parseLines()[0]["user"]).To(g.Equal("u1"))
+		})
+
+		It("should let the slog HTTP middleware log through logrus", func() {
+			mw := NewSLogChiMiddleware(slog.New(NewSLogLogrusHandler(logger)))
+			rec := httptest.NewRecorder()
+
+			mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			})).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+			logs := parseLines()
+			g.Expect(logs[0]["msg"]).To(g.Equal("HTTP Request Served"))
+			g.Expect(logs[0]["status"]).To(g.BeNumerically("==", http.StatusCreated))
+		})
+	})
+
+	Describe("NewSLogLogger", func() {
+		var sl *slog.Logger
+
+		BeforeEach(func() {
+			sl = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		})
+
+		parse := func() map[string]any {
+			var out map[string]any
+			g.Expect(json.Unmarshal(buf.Bytes(), &out)).To(g.Succeed())
+			return out
+		}
+
+		It("should log through slog with fields", func() {
+			NewSLogLogger(sl).WithField("a", 1).DebugWithFieldsf(Fields{"b": "2"}, "hello %s", "world")
+
+			out := parse()
+			g.Expect(out["level"]).To(g.Equal("DEBUG"))
+			g.Expect(out["msg"]).To(g.Equal("hello world"))
+			g.Expect(out["a"]).To(g.BeNumerically("==", 1))
+			g.Expect(out["b"]).To(g.Equal("2"))
+		})
+
+		It("should pass the context to the handler", func() {
+			ctx := WithContext(context.Background(), MakeField("user", "u1"))
+			l := slog.New(NewSLogContextHandler(sl.Handler(), nil))
+
+			NewSLogLogger(l).WithContext(ctx).Warn("with context")
+
+			g.Expect(parse()["user"]).To(g.Equal("u1"))
+		})
+
+		It("should back a PrefixedLogger", func() {
+			pl := NewPrefixedLogger("worker", NewSLogLogger(sl))
+			pl.Style = PrefixInMessageAndField
+
+			pl.ErrorWithFields(Fields{"job": "sync"}, "failed")
+
+			out := parse()
+			g.Expect(out["level"]).To(g.Equal("ERROR"))
+			g.Expect(out["msg"]).To(g.Equal("worker: failed"))
+			g.Expect(out["job"]).To(g.Equal("sync"))
+			g.Expect(out[ComponentFieldKey]).To(g.Equal("worker"))
+		})
+
+		It("should panic after logging", func() {
+			g.Expect(func() { NewSLogLogger(sl).Panic("boom") }).To(g.PanicWith("boom"))
+			g.Expect(parse()["msg"]).To(g.Equal("boom"))
+		})
+	})
+
+	Describe("WithSLogHandler", func() {
+		It("should forward logrus entries to slog", func() {
+			logger := NewLogger(WithSLogHandler(slog.NewJSONHandler(&buf, nil)))
+			ctx := WithContext(context.Background(), MakeField("user", "u1"))
+
+			logger.WithContext(ctx).WithField("a", 1).Warn("forwarded")
+			logger.Debug("hidden")
+
+			var out map[string]any
+			g.Expect(json.Unmarshal(buf.Bytes(), &out)).To(g.Succeed())
+			g.Expect(out["level"]).To(g.Equal("WARN"))
+			g.Expect(out["msg"]).To(g.Equal("forwarded"))
+			g.Expect(out["a"]).To(g.BeNumerically("==", 1))
+			g.Expect(out["user"]).To(g.Equal("u1"))
+		})
+	})
+})
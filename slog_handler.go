@@ -0,0 +1,128 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// SLogFieldPrecedence decides which value is kept when a context field and a record attr
+// share a key.
+type SLogFieldPrecedence int
+
+const (
+	// RecordAttrsWin drops context fields whose key is already used by the record or by
+	// attrs added with WithAttrs.
+	RecordAttrsWin SLogFieldPrecedence = iota
+	// ContextFieldsWin drops record attrs whose key is used by a context field. Attrs added
+	// with WithAttrs are already formatted by the wrapped handler and are kept.
+	ContextFieldsWin
+)
+
+// SLogContextHandlerOptions configures NewSLogContextHandler.
+type SLogContextHandlerOptions struct {
+	// Group nests the context fields under a group with this name. Empty adds them at
+	// the current level, in which case Precedence resolves key collisions.
+	Group string
+	// Precedence resolves collisions between context fields and record attrs.
+	Precedence SLogFieldPrecedence
+}
+
+// SLogContextHandler is a slog.Handler that adds the logging fields pushed with
+// WithContext, along with the chi request ID, to every record.
+type SLogContextHandler struct {
+	handler slog.Handler
+	opts    SLogContextHandlerOptions
+	keys    map[string]struct{}
+}
+
+var _ slog.Handler = (*SLogContextHandler)(nil)
+
+// NewSLogContextHandler wraps h so that records carry the logging fields of the context
+// they are logged with. If opts is nil, the default options are used.
+func NewSLogContextHandler(h slog.Handler, opts *SLogContextHandlerOptions) *SLogContextHandler {
+	if opts == nil {
+		opts = &SLogContextHandlerOptions{}
+	}
+	return &SLogContextHandler{handler: h, opts: *opts}
+}
+
+func (h *SLogContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *SLogContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := contextFields(ctx)
+	if len(fields) == 0 {
+		return h.handler.Handle(ctx, r)
+	}
+
+	attrs := fieldsToAttrs(fields)
+	if h.opts.Group != "" {
+		args := make([]any, len(attrs))
+		for i, a := range attrs {
+			args[i] = a
+		}
+		r.AddAttrs(slog.Group(h.opts.Group, args...))
+		return h.handler.Handle(ctx, r)
+	}
+
+	if h.opts.Precedence == ContextFieldsWin {
+		nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		r.Attrs(func(a slog.Attr) bool {
+			if _, ok := fields[a.Key]; !ok {
+				nr.AddAttrs(a)
+			}
+			return true
+		})
+		nr.AddAttrs(attrs...)
+		return h.handler.Handle(ctx, nr)
+	}
+
+	used := make(map[string]struct{}, len(h.keys)+r.NumAttrs())
+	for k := range h.keys {
+		used[k] = struct{}{}
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		used[a.Key] = struct{}{}
+		return true
+	})
+	for _, a := range attrs {
+		if _, ok := used[a.Key]; !ok {
+			r.AddAttrs(a)
+		}
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *SLogContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	keys := make(map[string]struct{}, len(h.keys)+len(attrs))
+	for k := range h.keys {
+		keys[k] = struct{}{}
+	}
+	for _, a := range attrs {
+		keys[a.Key] = struct{}{}
+	}
+	return &SLogContextHandler{handler: h.handler.WithAttrs(attrs), opts: h.opts, keys: keys}
+}
+
+// WithGroup opens a group on the wrapped handler. Context fields of later records are
+// qualified by the group, like any other record attr.
+func (h *SLogContextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SLogContextHandler{handler: h.handler.WithGroup(name), opts: h.opts}
+}
+
+// fieldsToAttrs converts fields into attrs sorted by key.
+func fieldsToAttrs(fields Fields) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for name, value := range fields {
+		attrs = append(attrs, slog.Any(name, value))
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+	return attrs
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/go-chi/chi/middleware"
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+)
+
+var _ = Describe("SLogContextHandler", func() {
+	var (
+		buf bytes.Buffer
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		ctx = context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+		ctx = WithContext(ctx, MakeField("user", "u1"), MakeField("tenant", "t1"))
+	})
+
+	newLogger := func(opts *SLogContextHandlerOptions) *slog.Logger {
+		return slog.New(NewSLogContextHandler(slog.NewJSONHandler(&buf, nil), opts))
+	}
+
+	parse := func() map[string]any {
+		var out map[string]any
+		g.Expect(json.Unmarshal(buf.Bytes(), &out)).To(g.Succeed())
+		return out
+	}
+
+	It("should add context fields and the request ID to records", func() {
+		newLogger(nil).InfoContext(ctx, "served", "status", 200)
+
+		out := parse()
+		g.Expect(out["msg"]).To(g.Equal("served"))
+		g.Expect(out["user"]).To(g.Equal("u1"))
+		g.Expect(out["tenant"]).To(g.Equal("t1"))
+		g.Expect(out["requestID"]).To(g.Equal("req-1"))
+		g.Expect(out["status"]).To(g.BeNumerically("==", 200))
+	})
+
+	It("should leave records without context fields untouched", func() {
+		newLogger(nil).Info("plain")
+
+		out := parse()
+		g.Expect(out).To(g.HaveLen(3))
+	})
+
+	It("should nest context fields under a group", func() {
+		newLogger(&SLogContextHandlerOptions{Group: "ctx"}).InfoContext(ctx, "served", "user", "record")
+
+		out := parse()
+		g.Expect(out["user"]).To(g.Equal("record"))
+		g.Expect(out["ctx"]).To(g.HaveKeyWithValue("user", "u1"))
+		g.Expect(out["ctx"]).To(g.HaveKeyWithValue("requestID", "req-1"))
+	})
+
+	It("should keep record attrs by default", func() {
+		newLogger(nil).With("tenant", "with").InfoContext(ctx, "served", "user", "record")
+
+		g.Expect(bytes.Count(buf.Bytes(), []byte(`"user"`))).To(g.Equal(1))
+		g.Expect(bytes.Count(buf.Bytes(), []byte(`"tenant"`))).To(g.Equal(1))
+		out := parse()
+		g.Expect(out["user"]).To(g.Equal("record"))
+		g.Expect(out["tenant"]).To(g.Equal("with"))
+	})
+
+	It("should keep context fields when configured", func() {
+		newLogger(&SLogContextHandlerOptions{Precedence: ContextFieldsWin}).
+			InfoContext(ctx, "served", "user", "record", "other", 1)
+
+		g.Expect(bytes.Count(buf.Bytes(), []byte(`"user"`))).To(g.Equal(1))
+		out := parse()
+		g.Expect(out["user"]).To(g.Equal("u1"))
+		g.Expect(out["other"]).To(g.BeNumerically("==", 1))
+	})
+
+	It("should qualify context fields by open groups", func() {
+		newLogger(nil).WithGroup("http").InfoContext(ctx, "served")
+
+		out := parse()
+		g.Expect(out["http"]).To(g.HaveKeyWithValue("user", "u1"))
+	})
+})
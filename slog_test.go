@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +13,7 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 	. "github.com/onsi/ginkgo"
 	g "github.com/onsi/gomega"
+	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
@@ -82,22 +82,44 @@ var _ = Describe("Logger", func() {
 	})
 
 	Describe("NewGraphQLResponseMiddleware", func() {
+		type logOutput struct {
+			Msg     string `json:"msg"`
+			Graphql struct {
+				Req struct {
+					OperationName string         `json:"operationName"`
+					OperationType string         `json:"operationType"`
+					Query         string         `json:"query"`
+					Variables     map[string]any `json:"variables"`
+				} `json:"req"`
+				Res struct {
+					Errors []map[string]any `json:"errors"`
+				} `json:"res"`
+				Duration int `json:"duration"`
+			} `json:"graphql"`
+		}
+
 		It("should log GraphQL response and request info", func() {
 			var (
 				buf    bytes.Buffer
 				logger = slog.New(slog.NewJSONHandler(&buf, nil))
-				query  = "query"
+				query  = "mutation CreateUser { createUser { id } }"
 				vars   = map[string]any{"token": "super secrect stuff"}
 				oc     = &graphql.OperationContext{
-					RawQuery:  query,
-					Variables: vars,
+					RawQuery:      query,
+					Variables:     vars,
+					OperationName: "CreateUser",
+					Operation:     &ast.OperationDefinition{Operation: ast.Mutation, Name: "CreateUser"},
 				}
 				errMsg = "Testing Errors"
 				errors = gqlerror.List{
 					&gqlerror.Error{
-						Err:     errors.New("error"),
-						Message: errMsg,
+						Err:        errors.New("error"),
+						Message:    errMsg,
+						Path:       ast.Path{ast.PathName("createUser"), ast.PathIndex(0), ast.PathName("id")},
+						Locations:  []gqlerror.Location{{Line: 1, Column: 23}},
+						Extensions: map[string]interface{}{"code": "FORBIDDEN"},
 					},
+					&gqlerror.Error{Message: "other"},
 				}
 				handler = func(ctx context.Context) *graphql.Response {
 					return &graphql.Response{
@@ -109,30 +131,51 @@ var _ = Describe("Logger", func() {
 
 			subject(graphql.WithOperationContext(context.Background(), oc), handler)
 
-			type logOutput struct {
-				Msg     string `json:"msg"`
-				Graphql struct {
-					Req struct {
-						Query     string         `json:"query"`
-						Variables map[string]any `json:"variables"`
-					} `json:"req"`
-					Res struct {
-						Errors string `json:"errors"`
-					} `json:"res"`
-					Duration int `json:"duration"`
-				} `json:"graphql"`
-			}
-
 			var lo logOutput
 			err := json.Unmarshal(buf.Bytes(), &lo)
 			g.Expect(err).To(g.Succeed())
 
 			g.Expect(lo.Msg).To(g.Equal("GraphQL Request Served"))
+			g.Expect(lo.Graphql.Req.OperationName).To(g.Equal("CreateUser"))
+			g.Expect(lo.Graphql.Req.OperationType).To(g.Equal("mutation"))
 			g.Expect(lo.Graphql.Req.Query).To(g.Equal(query))
 			g.Expect(lo.Graphql.Req.Variables).To(g.BeNil())
-			g.Expect(lo.Graphql.Res.Errors).To(g.Equal(fmt.Sprintf("input: %s\n", errMsg)))
+			g.Expect(lo.Graphql.Res.Errors).To(g.Equal([]map[string]any{
+				{
+					"message":   errMsg,
+					"path":      "createUser[0].id",
+					"locations": []any{map[string]any{"line": float64(1), "column": float64(23)}},
+					"code":      "FORBIDDEN",
+				},
+				{"message": "other"},
+			}))
 			g.Expect(lo.Graphql.Duration).To(g.BeNumerically(">", 0))
 		})
+
+		It("should fall back to the operation defined in the document", func() {
+			var (
+				buf    bytes.Buffer
+				logger = slog.New(slog.NewJSONHandler(&buf, nil))
+				oc     = &graphql.OperationContext{
+					RawQuery:  "query Users { users { id } }",
+					Operation: &ast.OperationDefinition{Operation: ast.Query, Name: "Users"},
+				}
+				handler = func(ctx context.Context) *graphql.Response {
+					return &graphql.Response{}
+				}
+				subject = NewSLogGraphQLResponseMiddleware(logger, nil)
+			)
+
+			subject(graphql.WithOperationContext(context.Background(), oc), handler)
+
+			var lo logOutput
+			err := json.Unmarshal(buf.Bytes(), &lo)
+			g.Expect(err).To(g.Succeed())
+
+			g.Expect(lo.Graphql.Req.OperationName).To(g.Equal("Users"))
+			g.Expect(lo.Graphql.Req.OperationType).To(g.Equal("query"))
+			g.Expect(lo.Graphql.Res.Errors).To(g.BeEmpty())
+		})
 	})
 
 	Describe("SLogReplaceAttr", func() {
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field names of the OpenTelemetry span correlation fields.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// addTraceFields adds the IDs of the OpenTelemetry span in ctx, if any, to fields.
+func addTraceFields(ctx context.Context, fields Fields) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	fields[TraceIDKey] = sc.TraceID().String()
+	fields[SpanIDKey] = sc.SpanID().String()
+	fields[TraceFlagsKey] = sc.TraceFlags().String()
+}
+
+// extractTraceparent adds the span context of the W3C traceparent and tracestate headers
+// to the request context, unless it already carries a span, so that the request is logged
+// and propagated under the caller's trace.
+func extractTraceparent(r *http.Request) *http.Request {
+	if trace.SpanContextFromContext(r.Context()).IsValid() || r.Header.Get("traceparent") == "" {
+		return r
+	}
+
+	ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return r
+	}
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	g "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("Trace correlation", func() {
+	var (
+		buf      bytes.Buffer
+		previous *logrus.Logger
+		exporter *tracetest.InMemoryExporter
+		tracer   trace.Tracer
+	)
+
+	BeforeEach(func() {
+		buf = bytes.Buffer{}
+		previous = Default()
+		SetDefault(NewLogger(WithJSONFormat(), WithOutput(&buf)))
+
+		exporter = tracetest.NewInMemoryExporter()
+		tracer = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("go-log")
+	})
+
+	AfterEach(func() {
+		SetDefault(previous)
+	})
+
+	lines := func() []map[string]interface{} {
+		b := ByteLogs{Log: &buf}
+		b.Parse(nil)
+		return b.Parsed
+	}
+
+	expectSpan := func(entry map[string]interface{}, sc trace.SpanContext) {
+		g.Expect(entry[TraceIDKey]).To(g.Equal(sc.TraceID().String()))
+		g.Expect(entry[SpanIDKey]).To(g.Equal(sc.SpanID().String()))
+		g.Expect(entry[TraceFlagsKey]).To(g.Equal("01"))
+	}
+
+	It("should add span IDs on every logging path", func() {
+		ctx, span := tracer.Start(context.Background(), "operation")
+		pl := NewPrefixedLogger("worker", Default())
+
+		InfoCtx(ctx, "package")
+		pl.InfoCtx(ctx, "prefixed")
+		Default().WithContext(ctx).Info("hook")
+		slog.New(NewSLogContextHandler(slog.NewJSONHandler(&buf, nil), nil)).InfoContext(ctx, "slog")
+		span.End()
+
+		spans := exporter.GetSpans()
+		g.Expect(spans).To(g.HaveLen(1))
+
+		logs := lines()
+		g.Expect(logs).To(g.HaveLen(4))
+		for _, entry := range logs {
+			expectSpan(entry, spans[0].SpanContext)
+		}
+	})
+
+	It("should not add span IDs without a span", func() {
+		InfoCtx(context.Background(), "no span")
+
+		g.Expect(lines()[0]).ToNot(g.HaveKey(TraceIDKey))
+	})
+
+	It("should pick up the traceparent header in the HTTP middlewares", func() {
+		var handlerCtx context.Context
+		mw := NewSLogChiMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlerCtx = r.Context()
+		})).ServeHTTP(httptest.NewRecorder(), r)
+
+		var out map[string]interface{}
+		g.Expect(json.Unmarshal(buf.Bytes(), &out)).To(g.Succeed())
+		g.Expect(out[TraceIDKey]).To(g.Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		g.Expect(out[SpanIDKey]).To(g.Equal("00f067aa0ba902b7"))
+
+		sc := trace.SpanContextFromContext(handlerCtx)
+		g.Expect(sc.IsRemote()).To(g.BeTrue())
+		g.Expect(sc.TraceID().String()).To(g.Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+	})
+
+	It("should prefer the span already in the request context", func() {
+		ctx, span := tracer.Start(context.Background(), "server")
+		defer span.End()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		ServerLogger()(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), r)
+
+		expectSpan(lines()[0], span.SpanContext())
+	})
+})